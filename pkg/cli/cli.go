@@ -10,16 +10,17 @@ import (
 )
 
 type CLI struct {
-	app      *cli.App
-	executor *watch.Executor
-	log      *logrus.Logger
+	app        *cli.App
+	executor   *watch.Executor
+	log        *logrus.Logger
+	configFile string
 }
 
 func New(log *logrus.Logger) *CLI {
 	app := cli.NewApp()
 	app.Name = "watch-web"
 	app.Usage = "Watch web updated"
-	c := &CLI{app, nil, log}
+	c := &CLI{app, nil, log, ""}
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "c,config",
@@ -48,6 +49,7 @@ func New(log *logrus.Logger) *CLI {
 			return fmt.Errorf("failed to load config file: %w", err)
 		}
 		c.executor = e
+		c.configFile = configFile
 		log.WithFields(logrus.Fields{
 			"file": configFile,
 		}).Debug("Config file loaded.")
@@ -57,6 +59,7 @@ func New(log *logrus.Logger) *CLI {
 		c.start(),
 		c.run(),
 		c.list(),
+		c.history(),
 	}
 	return c
 }
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+func (c *CLI) history() cli.Command {
+	return cli.Command{
+		Name:      "history",
+		ArgsUsage: "JOB_ID",
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "n,limit",
+				Value: 20,
+				Usage: "the number of the runs to show from the newest, 0 shows all",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 1 {
+				return errors.New("specify a job id")
+			}
+			jobID := ctx.Args().First()
+			limit := ctx.Int("limit")
+			if limit < 0 {
+				return fmt.Errorf("limit must not be negative: %d", limit)
+			}
+			runs, err := c.executor.GetJobRuns(jobID, limit)
+			if err != nil {
+				return fmt.Errorf("failed to get job history: %w", err)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+			fmt.Fprintln(w, "START\tDURATION\tSTATUS\tITEMS\tUPDATES\tERROR")
+			for _, run := range runs {
+				errMsg := ""
+				if run.Error != nil {
+					errMsg = *run.Error
+				}
+				duration := time.Duration(run.Duration * float64(time.Second)).Round(time.Millisecond)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", run.Start.Format(time.RFC3339), duration, run.Status, run.Items, len(run.Updates), errMsg)
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli"
 )
@@ -16,13 +17,36 @@ func (c *CLI) run() cli.Command {
 			cli.BoolFlag{
 				Name: "t,test-action",
 			},
+			cli.BoolFlag{
+				Name:  "d,due",
+				Usage: "check only the jobs due according to their schedules and the last checks in the store",
+			},
+			cli.DurationFlag{
+				Name:  "tolerance",
+				Usage: "check the jobs due within this duration too with --due, e.g. the interval of the external scheduler",
+			},
 		},
 		Action: func(ctx *cli.Context) error {
 			all := ctx.Bool("all")
+			due := ctx.Bool("due")
 			testAction := ctx.Bool("test-action")
 			exe := c.executor
 			if all {
 				exe.CheckAll()
+			} else if due {
+				jobs, err := exe.OverdueJobs(time.Now().Add(ctx.Duration("tolerance")), 0)
+				if err != nil {
+					return err
+				}
+				errs := exe.CheckJobs(jobs)
+				for i, job := range jobs {
+					dto := newJobCheckDTO(job, errs[i])
+					if dto.Error != "" {
+						fmt.Printf("%s: %s (%s)\n", dto.ID, dto.Status, dto.Error)
+					} else {
+						fmt.Printf("%s: %s\n", dto.ID, dto.Status)
+					}
+				}
 			} else {
 				for _, id := range ctx.Args() {
 					job := exe.Job(id)
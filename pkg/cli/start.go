@@ -1,12 +1,19 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"regexp"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/uphy/watch-web/pkg/config"
 	"github.com/uphy/watch-web/pkg/domain"
 	"github.com/uphy/watch-web/pkg/watch"
 	"github.com/urfave/cli"
@@ -21,6 +28,18 @@ type (
 		Error  *string       `json:"error,omitempty"`
 		Last   *time.Time    `json:"last,omitempty"`
 		Count  int           `json:"count"`
+		// Pending are the updates not confirmed yet.
+		Pending []domain.PendingUpdate `json:"pending,omitempty"`
+		// Interval is the current interval in seconds of the adaptive schedule.
+		Interval float64 `json:"interval,omitempty"`
+		// Failures is the number of the consecutive failures.
+		Failures int `json:"failures,omitempty"`
+	}
+	// JobCheckDTO is the result of the job check requested by API.
+	JobCheckDTO struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
 	}
 	JobDetailDTO struct {
 		*JobDTO
@@ -28,8 +47,17 @@ type (
 	}
 )
 
+// defaultShutdownTimeout is shorter than the grace period of Heroku (30s) to finish before killed.
+const defaultShutdownTimeout = 25 * time.Second
+
+// defaultReloadInterval is the default interval to check the changes of the config files.
+const defaultReloadInterval = 10 * time.Second
+
 func getJob(exe *watch.Executor, jobID string) (*JobDTO, error) {
-	job := exe.Jobs[jobID]
+	job := exe.Job(jobID)
+	if job == nil {
+		return nil, nil
+	}
 	status, err := exe.GetJobStatus(jobID)
 	if err != nil {
 		return nil, err
@@ -40,20 +68,23 @@ func getJob(exe *watch.Executor, jobID string) (*JobDTO, error) {
 		}
 	}
 	return &JobDTO{
-		ID:     job.ID(),
-		Link:   job.Info.Link,
-		Label:  job.Info.Label,
-		Status: status.Status,
-		Error:  status.Error,
-		Last:   status.Last,
-		Count:  status.Count,
+		ID:       job.ID(),
+		Link:     job.Info.Link,
+		Label:    job.Info.Label,
+		Status:   status.Status,
+		Error:    status.Error,
+		Last:     status.Last,
+		Count:    status.Count,
+		Pending:  status.Pending,
+		Interval: status.Interval,
+		Failures: status.Failures,
 	}, nil
 }
 
 func getJobs(exe *watch.Executor) ([]JobDTO, error) {
 	jobs := []JobDTO{}
-	for jobID := range exe.Jobs {
-		job, err := getJob(exe, jobID)
+	for _, j := range exe.Jobs() {
+		job, err := getJob(exe, j.ID())
 		if err != nil {
 			return nil, err
 		}
@@ -64,7 +95,7 @@ func getJobs(exe *watch.Executor) ([]JobDTO, error) {
 
 func getJobDetail(exe *watch.Executor, jobID string) (*JobDetailDTO, error) {
 	job, err := getJob(exe, jobID)
-	if err != nil {
+	if err != nil || job == nil {
 		return nil, err
 	}
 	value, err := exe.GetJobValue(jobID)
@@ -74,6 +105,19 @@ func getJobDetail(exe *watch.Executor, jobID string) (*JobDetailDTO, error) {
 	return &JobDetailDTO{job, value}, nil
 }
 
+func newJobCheckDTO(job *watch.Job, err error) JobCheckDTO {
+	switch err {
+	case nil:
+		return JobCheckDTO{ID: job.ID(), Status: "checked"}
+	case watch.ErrAlreadyRunning:
+		return JobCheckDTO{ID: job.ID(), Status: "already running"}
+	case watch.ErrShuttingDown:
+		return JobCheckDTO{ID: job.ID(), Status: "shutting down"}
+	default:
+		return JobCheckDTO{ID: job.ID(), Status: "failed", Error: err.Error()}
+	}
+}
+
 func (c *CLI) start() cli.Command {
 	return cli.Command{
 		Name: "start",
@@ -89,88 +133,211 @@ func (c *CLI) start() cli.Command {
 				Value:  8080,
 				EnvVar: "PORT",
 			},
+			cli.DurationFlag{
+				Name:  "reload-interval",
+				Usage: "interval to check the changes of the config files to reload. 0 disables the check but SIGHUP still reloads",
+				Value: defaultReloadInterval,
+			},
+			cli.DurationFlag{
+				Name:  "shutdown-timeout",
+				Usage: "maximum time to wait for the running checks on SIGTERM/SIGINT",
+				Value: defaultShutdownTimeout,
+			},
 		},
 		Action: func(ctx *cli.Context) error {
 			enableAPI := ctx.Bool("api")
 			enableSchedule := !ctx.Bool("no-schedule")
 			exe := c.executor
-
-			if !enableAPI {
-				if enableSchedule {
-					exe.Run()
-				}
+			if !enableAPI && !enableSchedule {
 				return nil
 			}
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+			defer signal.Stop(signals)
+
 			if enableSchedule {
 				go exe.Run()
 			}
-
-			port := ctx.Int("port")
-			e := echo.New()
-			e.Pre(middleware.RemoveTrailingSlash())
-			e.Use(middleware.Logger())
-			e.GET("/api/jobs", func(ctx echo.Context) error {
-				jobs, err := getJobs(exe)
-				if err != nil {
-					return err
-				}
-				return ctx.JSON(200, jobs)
-			})
-			e.POST("/api/jobs/check-all", func(ctx echo.Context) error {
-				v, err := ctx.FormParams()
-				if err != nil {
-					return err
-				}
-				p := v.Get("pattern")
-				ptn, err := regexp.Compile(p)
-				if err != nil {
-					return err
-				}
-				checked := make([]*watch.Job, 0)
-				for _, j := range exe.Jobs {
-					if !ptn.Match([]byte(j.ID())) {
-						continue
+			stopReload := c.watchConfig(ctx.Duration("reload-interval"))
+			defer stopReload()
+			var e *echo.Echo
+			errs := make(chan error, 1)
+			if enableAPI {
+				e = newAPIServer(exe)
+				go func() {
+					if err := e.Start(fmt.Sprintf(":%d", ctx.Int("port"))); err != nil && err != http.ErrServerClosed {
+						errs <- err
 					}
-					exe.Check(j)
-					checked = append(checked, j)
-				}
-				return ctx.JSON(200, checked)
-			})
-			e.GET("/api/jobs/:jobID", func(ctx echo.Context) error {
-				jobID := ctx.Param("jobID")
-				job, err := getJobDetail(exe, jobID)
-				if err != nil {
-					return err
-				}
-				if job == nil {
-					return echo.NewHTTPError(404, "specified job is not exist")
-				}
-				return ctx.JSON(200, job)
-			})
-			e.POST("/api/jobs/:name/check", func(ctx echo.Context) error {
-				name := ctx.Param("name")
-				job := exe.Job(name)
-				if job == nil {
-					return echo.NewHTTPError(404, "specified job is not exist")
-				}
-				result, err := exe.Check(job)
-				if err != nil {
-					return echo.NewHTTPError(500, "failed to check: "+err.Error())
-				}
-				return ctx.JSON(200, result)
-			})
-			e.POST("/api/jobs/:name/test-actions", func(ctx echo.Context) error {
-				name := ctx.Param("name")
-				job := exe.Job(name)
-				if job == nil {
-					return echo.NewHTTPError(404, "specified job is not exist")
-				}
-				if err := exe.TestActions(job); err != nil {
-					return echo.NewHTTPError(500, err)
+				}()
+			}
+
+			select {
+			case sig := <-signals:
+				c.log.WithField("signal", sig).Info("Shutting down.")
+			case err := <-errs:
+				exe.Stop()
+				return err
+			}
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), ctx.Duration("shutdown-timeout"))
+			defer cancel()
+			// stop starting new checks before waiting for the API requests which may be running checks.
+			exe.Stop()
+			if e != nil {
+				if err := e.Shutdown(shutdownCtx); err != nil {
+					c.log.WithField("err", err).Warn("Failed to shutdown API server.")
 				}
-				return ctx.NoContent(200)
-			})
-			return e.Start(fmt.Sprintf(":%d", port))
+			}
+			return exe.Shutdown(shutdownCtx)
 		},
 	}
 }
+
+// watchConfig reloads the jobs when the config files are changed or SIGHUP is received.
+// The current jobs keep running if the config is invalid.
+// Returns the function to stop watching.
+func (c *CLI) watchConfig(interval time.Duration) func() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	var watcher *config.Watcher
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+		watcher = config.NewWatcher(c.configFile)
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-hup:
+				c.log.Info("Received SIGHUP.")
+			case <-tick:
+				if !watcher.Changed() {
+					continue
+				}
+				c.log.Info("Config files changed.")
+			}
+			if err := config.LoadAndReload(c.log, c.configFile, c.executor); err != nil {
+				c.log.WithField("err", err).Error("Failed to reload config.  Keep running with the current config.")
+				continue
+			}
+			c.log.WithField("file", c.configFile).Info("Reloaded config.")
+		}
+	}()
+	return func() {
+		signal.Stop(hup)
+		if ticker != nil {
+			ticker.Stop()
+		}
+		close(done)
+	}
+}
+
+func newAPIServer(exe *watch.Executor) *echo.Echo {
+	e := echo.New()
+	e.Pre(middleware.RemoveTrailingSlash())
+	e.Use(middleware.Logger())
+	e.GET("/api/jobs", func(ctx echo.Context) error {
+		jobs, err := getJobs(exe)
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(200, jobs)
+	})
+	e.POST("/api/jobs/check-all", func(ctx echo.Context) error {
+		v, err := ctx.FormParams()
+		if err != nil {
+			return err
+		}
+		p := v.Get("pattern")
+		ptn, err := regexp.Compile(p)
+		if err != nil {
+			return err
+		}
+		checked := make([]*watch.Job, 0)
+		for _, j := range exe.Jobs() {
+			if !ptn.Match([]byte(j.ID())) {
+				continue
+			}
+			checked = append(checked, j)
+		}
+		errs := exe.CheckJobs(checked)
+		results := make([]JobCheckDTO, len(checked))
+		for i, j := range checked {
+			results[i] = newJobCheckDTO(j, errs[i])
+		}
+		return ctx.JSON(200, results)
+	})
+	e.GET("/api/jobs/:jobID", func(ctx echo.Context) error {
+		jobID := ctx.Param("jobID")
+		job, err := getJobDetail(exe, jobID)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return echo.NewHTTPError(404, "specified job is not exist")
+		}
+		return ctx.JSON(200, job)
+	})
+	e.GET("/api/jobs/:jobID/history", func(ctx echo.Context) error {
+		jobID := ctx.Param("jobID")
+		if exe.Job(jobID) == nil {
+			return echo.NewHTTPError(404, "specified job is not exist")
+		}
+		limit := 0
+		if l := ctx.QueryParam("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n < 0 {
+				return echo.NewHTTPError(400, "invalid limit: "+l)
+			}
+			limit = n
+		}
+		runs, err := exe.GetJobRuns(jobID, limit)
+		if err != nil {
+			return err
+		}
+		if runs == nil {
+			runs = []domain.JobRun{}
+		}
+		return ctx.JSON(200, runs)
+	})
+	e.POST("/api/jobs/:name/check", func(ctx echo.Context) error {
+		name := ctx.Param("name")
+		job := exe.Job(name)
+		if job == nil {
+			return echo.NewHTTPError(404, "specified job is not exist")
+		}
+		result, err := exe.Check(job)
+		if err == watch.ErrAlreadyRunning {
+			return ctx.JSON(409, newJobCheckDTO(job, err))
+		}
+		if err == watch.ErrShuttingDown {
+			return echo.NewHTTPError(503, err.Error())
+		}
+		if err != nil {
+			return echo.NewHTTPError(500, "failed to check: "+err.Error())
+		}
+		return ctx.JSON(200, result)
+	})
+	e.POST("/api/jobs/:name/test-actions", func(ctx echo.Context) error {
+		name := ctx.Param("name")
+		job := exe.Job(name)
+		if job == nil {
+			return echo.NewHTTPError(404, "specified job is not exist")
+		}
+		if err := exe.TestActions(job); err != nil {
+			return echo.NewHTTPError(500, err)
+		}
+		return ctx.NoContent(200)
+	})
+	e.GET("/metrics", func(ctx echo.Context) error {
+		ctx.Response().Header().Set(echo.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		ctx.Response().WriteHeader(200)
+		return exe.WriteMetrics(ctx.Response())
+	})
+	return e
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"bytes"
 	"io"
+	"time"
+
+	"github.com/uphy/watch-web/pkg/watch"
 
 	"github.com/uphy/watch-web/pkg/domain/template"
 
@@ -15,9 +18,75 @@ type (
 		InitialRun *template.TemplateString `json:"initial_run,omitempty"`
 		Actions    []ActionConfig           `json:"actions"`
 		Store      *StoreConfig             `json:"store"`
+		Workers    *WorkersConfig           `json:"workers,omitempty"`
+		Lock       *LockConfig              `json:"lock,omitempty"`
+		CatchUp    *CatchUpConfig           `json:"catch_up,omitempty"`
+		History    *HistoryConfig           `json:"history,omitempty"`
+		// AlertAfter is the default number of the consecutive failures to notify the failure.  0 means no notification.
+		AlertAfter int `json:"alert_after,omitempty"`
+		// ScheduleOptionsConfig are the defaults of the schedule options of the jobs.
+		ScheduleOptionsConfig
+	}
+	// CatchUpConfig checks the jobs which missed the scheduled runs on startup.
+	// It is ignored if initial_run is true because all jobs are checked.
+	CatchUpConfig struct {
+		Enabled bool `json:"enabled,omitempty"`
+		// MaxLateness is the limit of the delay in seconds of the missed runs to catch up.
+		MaxLateness float64 `json:"max_lateness,omitempty"`
+	}
+	// HistoryConfig is the retention of the run history of the jobs.
+	HistoryConfig struct {
+		// MaxRuns is the maximum number of the runs kept for each job.  Defaults to 100.
+		MaxRuns int `json:"max_runs,omitempty"`
+		// MaxAge is the maximum age of the runs in seconds.  0 means no limit.
+		MaxAge float64 `json:"max_age,omitempty"`
+	}
+	// LockConfig is the lock of the running jobs across the instances.
+	LockConfig struct {
+		// Distributed locks the running jobs with the store.  Only redis store supports it.
+		Distributed bool `json:"distributed,omitempty"`
+		// TTL is the expiration of the lock in seconds.
+		TTL float64 `json:"ttl,omitempty"`
+		// LeaderElection runs the schedule only on the leader elected among the instances sharing the store.
+		LeaderElection bool `json:"leader_election,omitempty"`
+		// LeaderTTL is the expiration of the leader lease in seconds.
+		LeaderTTL float64 `json:"leader_ttl,omitempty"`
+	}
+	// WorkersConfig limits the concurrent checks.
+	WorkersConfig struct {
+		// Count is the number of the jobs checked concurrently.  Defaults to 1.
+		Count int `json:"count,omitempty"`
+		// PerHost is the default limit for each host derived from the source URL or the job link.
+		PerHost *HostLimitConfig `json:"per_host,omitempty"`
+		// Hosts are the limits for the specific hosts.
+		Hosts map[string]HostLimitConfig `json:"hosts,omitempty"`
+	}
+	HostLimitConfig struct {
+		// Concurrency is the maximum number of the jobs for the host running concurrently.
+		Concurrency int `json:"concurrency,omitempty"`
+		// MinDelay is the minimum interval in seconds between the starts of the jobs for the host.
+		MinDelay float64 `json:"min_delay,omitempty"`
 	}
 )
 
+func (w *WorkersConfig) newWorkerPool() *watch.WorkerPool {
+	pool := watch.NewWorkerPool(w.Count)
+	if w.PerHost != nil {
+		pool.PerHost = w.PerHost.hostLimit()
+	}
+	for host, limit := range w.Hosts {
+		pool.Hosts[host] = limit.hostLimit()
+	}
+	return pool
+}
+
+func (h HostLimitConfig) hostLimit() watch.HostLimit {
+	return watch.HostLimit{
+		Concurrency: h.Concurrency,
+		MinDelay:    time.Duration(h.MinDelay * float64(time.Second)),
+	}
+}
+
 func (c *Config) Save(w io.Writer) error {
 	data, err := yaml.Marshal(c)
 	if err != nil {
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/uphy/watch-web/pkg/domain/value"
+)
+
+const (
+	defaultMaskReplacement = "<masked>"
+)
+
+type (
+	// DiffConfig is the job specific diff settings.
+	DiffConfig struct {
+		// Engine is either of 'item'(default) or 'json'.
+		// 'json' compares the nested JSON values structurally and can't be combined with the other settings.
+		Engine string `json:"engine,omitempty"`
+		// Rules are the conditions to report the changes of the numeric fields.
+		// Changes of the keys which have no rules are always reported.
+		Rules []ChangeRuleConfig `json:"rules,omitempty"`
+		// Ignore excludes the volatile content such as timestamps from the comparison.
+		Ignore *DiffIgnoreConfig `json:"ignore,omitempty"`
+		// Move is how to report the items moved to the different positions.
+		// The moves are reported only if it is specified.
+		Move *MoveConfig `json:"move,omitempty"`
+		// Match pairs the similar removed and added items without IDs and reports them as changes.
+		Match *MatchConfig `json:"match,omitempty"`
+	}
+	ChangeRuleConfig struct {
+		Key       string   `json:"key"`
+		Absolute  *float64 `json:"absolute,omitempty"`
+		Percent   *float64 `json:"percent,omitempty"`
+		Direction string   `json:"direction,omitempty"`
+		Below     *float64 `json:"below,omitempty"`
+		Above     *float64 `json:"above,omitempty"`
+		Cross     *float64 `json:"cross,omitempty"`
+	}
+	DiffIgnoreConfig struct {
+		// Keys are the item keys not to be compared.
+		Keys []string `json:"keys,omitempty"`
+		// KeyPatterns are the glob patterns of the item keys not to be compared.
+		KeyPatterns []string `json:"key_patterns,omitempty"`
+		// Values are the regex masks of the values.
+		Values []ValueMaskConfig `json:"values,omitempty"`
+	}
+	MoveConfig struct {
+		// Mode is either of 'report'(default) or 'ignore'.
+		Mode string `json:"mode,omitempty"`
+		// MinDistance reports only the moves beyond this number of positions.
+		MinDistance int `json:"min_distance,omitempty"`
+	}
+	MatchConfig struct {
+		// Fields are the keys to compute the similarity.  Defaults to all keys.
+		Fields []string `json:"fields,omitempty"`
+		// Threshold is the minimum similarity(0 to 1) to pair items.  Defaults to 0.8.
+		Threshold float64 `json:"threshold,omitempty"`
+	}
+	ValueMaskConfig struct {
+		Pattern string `json:"pattern"`
+		// Replacement replaces the matched substrings.  Defaults to '<masked>'.
+		Replacement *string `json:"replacement,omitempty"`
+	}
+)
+
+func (d *DiffConfig) newDiffer() (*value.Differ, error) {
+	rules := make(value.ChangeRules, 0, len(d.Rules))
+	for _, r := range d.Rules {
+		if r.Key == "" {
+			return nil, fmt.Errorf("key is required for diff rule: %#v", r)
+		}
+		direction, err := value.ParseDirection(r.Direction)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, value.ChangeRule{
+			Key:       r.Key,
+			Absolute:  r.Absolute,
+			Percent:   r.Percent,
+			Direction: direction,
+			Below:     r.Below,
+			Above:     r.Above,
+			Cross:     r.Cross,
+		})
+	}
+	engine, err := value.ParseDiffEngine(d.Engine)
+	if err != nil {
+		return nil, err
+	}
+	if engine == value.DiffEngineJSON && (len(d.Rules) > 0 || d.Ignore != nil || d.Move != nil || d.Match != nil) {
+		return nil, fmt.Errorf("rules, ignore, move and match are not supported by %s diff engine", engine)
+	}
+	differ := &value.Differ{Engine: engine, Rules: rules}
+	if d.Ignore != nil {
+		ignore, err := d.Ignore.newIgnoreRule()
+		if err != nil {
+			return nil, err
+		}
+		differ.Ignore = ignore
+	}
+	if d.Move != nil {
+		mode, err := value.ParseMoveMode(d.Move.Mode)
+		if err != nil {
+			return nil, err
+		}
+		differ.Move = &value.MoveOption{Mode: mode, MinDistance: d.Move.MinDistance}
+	}
+	if d.Match != nil {
+		if d.Match.Threshold < 0 || d.Match.Threshold > 1 {
+			return nil, fmt.Errorf("match threshold must be between 0 and 1: threshold=%v", d.Match.Threshold)
+		}
+		differ.Matcher = &value.SimilarityMatcher{Fields: d.Match.Fields, Threshold: d.Match.Threshold}
+	}
+	return differ, nil
+}
+
+func (i *DiffIgnoreConfig) newIgnoreRule() (*value.IgnoreRule, error) {
+	masks := make([]value.ValueMask, 0, len(i.Values))
+	for _, v := range i.Values {
+		p, err := regexp.Compile(v.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value pattern: pattern=%s, err=%w", v.Pattern, err)
+		}
+		replacement := defaultMaskReplacement
+		if v.Replacement != nil {
+			replacement = *v.Replacement
+		}
+		masks = append(masks, value.ValueMask{Pattern: p, Replacement: replacement})
+	}
+	return value.NewIgnoreRule(i.Keys, i.KeyPatterns, masks)
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
+
 	"github.com/uphy/watch-web/pkg/domain/template"
+	"github.com/uphy/watch-web/pkg/watch"
 )
 
 type (
@@ -12,7 +15,57 @@ type (
 		Source    *SourceConfig           `json:"source,omitempty"`
 		Schedule  template.TemplateString `json:"schedule,omitempty"`
 		WithItems []interface{}           `json:"with_items,omitempty"`
-		Actions   []ActionConfig          `json:"actions",omitempty`
+		Actions   []ActionConfig          `json:"actions,omitempty"`
 		Enable    *bool                   `json:"enable,omitempty"`
+		Guard     *GuardConfig            `json:"guard,omitempty"`
+		Diff      *DiffConfig             `json:"diff,omitempty"`
+		// ConfirmAfter reports the update only after it is observed in this number of consecutive checks.
+		ConfirmAfter int `json:"confirm_after,omitempty"`
+		// RunPolicy is either of 'skip'(default), 'queue' or 'wait' for the run requested while the job is running.
+		RunPolicy string `json:"run_policy,omitempty"`
+		// Timeout is the maximum duration of a check in seconds including the actions.
+		Timeout float64 `json:"timeout,omitempty"`
+		// Adaptive schedules the job by the change frequency instead of the schedule.
+		Adaptive *AdaptiveConfig `json:"adaptive,omitempty"`
+		// AlertAfter notifies the failure with the actions after this number of consecutive failures.
+		// Overrides the top level one and 0 disables the notification.
+		AlertAfter *int `json:"alert_after,omitempty"`
+		ScheduleOptionsConfig
+	}
+	// AdaptiveConfig checks the job at MinInterval after changes and backs off up to MaxInterval while unchanged.
+	AdaptiveConfig struct {
+		// MinInterval and MaxInterval are the bounds of the interval in seconds.
+		MinInterval float64 `json:"min_interval"`
+		MaxInterval float64 `json:"max_interval"`
+		// Backoff is the multiplier of the interval while unchanged.  Defaults to 2.
+		Backoff float64 `json:"backoff,omitempty"`
+	}
+	// GuardConfig is the sanity check of the fetched value.
+	// If the value violates the guard, the job fails and the previous value is kept.
+	GuardConfig struct {
+		MinItems          *int     `json:"min_items,omitempty"`
+		MaxItems          *int     `json:"max_items,omitempty"`
+		MaxRemovedPercent *float64 `json:"max_removed_percent,omitempty"`
+		MaxAddedPercent   *float64 `json:"max_added_percent,omitempty"`
+		RequiredKeys      []string `json:"required_keys,omitempty"`
+		// Notify sends a notification with the actions once when the guard is violated.
+		Notify bool `json:"notify,omitempty"`
 	}
 )
+
+func (a *AdaptiveConfig) newAdaptiveSchedule() (*watch.AdaptiveSchedule, error) {
+	if a.MinInterval <= 0 {
+		return nil, errors.New("min_interval must be positive")
+	}
+	if a.MaxInterval < a.MinInterval {
+		return nil, errors.New("max_interval must not be less than min_interval")
+	}
+	if a.Backoff != 0 && a.Backoff < 1 {
+		return nil, errors.New("backoff must not be less than 1")
+	}
+	return &watch.AdaptiveSchedule{
+		MinInterval: seconds(a.MinInterval),
+		MaxInterval: seconds(a.MaxInterval),
+		Backoff:     a.Backoff,
+	}, nil
+}
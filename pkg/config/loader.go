@@ -1,6 +1,8 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -8,8 +10,10 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/uphy/watch-web/pkg/domain/retry"
+	"github.com/uphy/watch-web/pkg/domain/schema"
 	"github.com/uphy/watch-web/pkg/domain/template"
 
 	"github.com/uphy/watch-web/pkg/domain/value"
@@ -33,6 +37,10 @@ type (
 		ctx             *template.TemplateContext
 		configDirectory *configDirectory
 		store           domain.Store
+		// scheduleDefaults are the schedule options for all jobs.
+		scheduleDefaults *ScheduleOptionsConfig
+		// alertAfter is the default of the job's AlertAfter.
+		alertAfter int
 	}
 )
 
@@ -46,10 +54,21 @@ func LoadAndCreate(log *logrus.Logger, file string) (*watch.Executor, error) {
 	return l.Create(conf)
 }
 
+// LoadAndReload loads the config file again and replaces the jobs of the executor created by LoadAndCreate.
+func LoadAndReload(log *logrus.Logger, file string, e *watch.Executor) error {
+	baseDirectory, _ := filepath.Split(file)
+	l := NewLoader(log, baseDirectory)
+	conf, err := l.Load(file)
+	if err != nil {
+		return err
+	}
+	return l.Reload(conf, e)
+}
+
 func NewLoader(log *logrus.Logger, file string) *Loader {
 	ctx := template.NewRootTemplateContext()
 	dir, _ := filepath.Split(file)
-	return &Loader{log, ctx, newConfigDirectory(dir), nil}
+	return &Loader{log, ctx, newConfigDirectory(dir), nil, nil, 0}
 }
 
 func (l *Loader) TemplateContext() *template.TemplateContext {
@@ -66,10 +85,15 @@ func (l *Loader) Load(file string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 	data, err := ioutil.ReadAll(f)
 	if err != nil {
 		return nil, err
 	}
+	// the file may be read while being written on reloading.
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("config file is empty: file=%s", file)
+	}
 
 	// parse yaml/json
 	var config Config
@@ -109,13 +133,78 @@ func (l *Loader) Create(c *Config) (*watch.Executor, error) {
 		}
 		e.InitialRun = ini
 	}
+	if c.Workers != nil {
+		e.Pool = c.Workers.newWorkerPool()
+	}
+	if c.CatchUp != nil {
+		if c.CatchUp.MaxLateness < 0 {
+			return nil, errors.New("max_lateness must not be negative")
+		}
+		e.CatchUp = c.CatchUp.Enabled
+		e.MaxLateness = seconds(c.CatchUp.MaxLateness)
+	}
+	if c.History != nil {
+		if c.History.MaxRuns < 0 || c.History.MaxAge < 0 {
+			return nil, errors.New("history retention must not be negative")
+		}
+		e.History = domain.HistoryRetention{
+			MaxRuns: c.History.MaxRuns,
+			MaxAge:  seconds(c.History.MaxAge),
+		}
+	}
+	if c.Lock != nil {
+		e.DistributedLock = c.Lock.Distributed
+		e.LockTTL = seconds(c.Lock.TTL)
+		if c.Lock.LeaderElection {
+			leaser, ok := store.(domain.Leaser)
+			if !ok {
+				return nil, fmt.Errorf("leader election is not supported by the store: store=%T", store)
+			}
+			leader := watch.NewLeaderElector(leaser, l.log)
+			if c.Lock.LeaderTTL > 0 {
+				leader.TTL = seconds(c.Lock.LeaderTTL)
+			}
+			e.Leader = leader
+		}
+	}
 
 	// jobs
+	jobs, err := l.createJobs(c, actions)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.ReplaceJobs(jobs); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reload creates the jobs from the config and replaces the jobs of the running executor.
+// The store of the executor is reused so that the values and the statuses of the jobs are kept.
+// The settings of the executor such as the store, the workers and the lock are not changed until restart.
+// If the config is invalid, returns the error and the executor keeps the current jobs.
+func (l *Loader) Reload(c *Config, e *watch.Executor) error {
+	l.store = e.Store()
+	actions, err := l.createActions(c.Actions)
+	if err != nil {
+		return err
+	}
+	jobs, err := l.createJobs(c, actions)
+	if err != nil {
+		return err
+	}
+	return e.ReplaceJobs(jobs)
+}
+
+func (l *Loader) createJobs(c *Config, actions []domain.Action) ([]*watch.Job, error) {
+	l.scheduleDefaults = &c.ScheduleOptionsConfig
+	l.alertAfter = c.AlertAfter
+	all := make([]*watch.Job, 0)
 	for _, jobConfig := range c.Jobs {
 		if jobConfig.Enable != nil && !*jobConfig.Enable {
 			continue
 		}
-		jobs, err := l.addJobTo(&jobConfig, e, actions)
+		jobs, err := l.createJob(&jobConfig, actions)
 		if err != nil {
 			return nil, err
 		}
@@ -124,9 +213,10 @@ func (l *Loader) Create(c *Config) (*watch.Executor, error) {
 		}
 		l.log.WithFields(logrus.Fields{
 			"jobs": jobs,
-		}).Debug("Added jobs to executor.")
+		}).Debug("Created jobs.")
+		all = append(all, jobs...)
 	}
-	return e, nil
+	return all, nil
 }
 
 func (l *Loader) createStore(config *StoreConfig) (domain.Store, error) {
@@ -236,10 +326,10 @@ func (l *Loader) createActionSlackBot(s *SlackBotActionConfig) (domain.Action, e
 	return action.NewSlackBotAction(token, channel, s.Debug, repo), nil
 }
 
-func (l *Loader) addJobTo(c *JobConfig, e *watch.Executor, actions []domain.Action) ([]*watch.Job, error) {
+func (l *Loader) createJob(c *JobConfig, actions []domain.Action) ([]*watch.Job, error) {
 	jobs := make([]*watch.Job, 0)
 	if c.WithItems == nil {
-		job, err := l.addJobOne(c, e, actions)
+		job, err := l.createJobOne(c, actions)
 		if err != nil {
 			return nil, err
 		}
@@ -253,7 +343,7 @@ func (l *Loader) addJobTo(c *JobConfig, e *watch.Executor, actions []domain.Acti
 			l.ctx.PushScope()
 			l.ctx.Set("itemIndex", itemIndex)
 			l.ctx.Set("item", evaluatedItem)
-			j, err := l.addJobOne(c, e, actions)
+			j, err := l.createJobOne(c, actions)
 			if err != nil {
 				return nil, err
 			}
@@ -288,7 +378,7 @@ func evaluateItemAsTemplate(ctx *template.TemplateContext, v interface{}) (inter
 	return e, nil
 }
 
-func (l *Loader) addJobOne(c *JobConfig, e *watch.Executor, actions []domain.Action) (*watch.Job, error) {
+func (l *Loader) createJobOne(c *JobConfig, actions []domain.Action) (*watch.Job, error) {
 	source, err := l.CreateSource(c.Source)
 	if err != nil {
 		return nil, err
@@ -321,9 +411,58 @@ func (l *Loader) addJobOne(c *JobConfig, e *watch.Executor, actions []domain.Act
 		Label: label,
 		Link:  link,
 	}, source, actions)
-
-	if err := e.AddJob(job, &schedule); err != nil {
-		return nil, err
+	if c.Guard != nil {
+		job.Guard = &watch.Guard{
+			MinItems:          c.Guard.MinItems,
+			MaxItems:          c.Guard.MaxItems,
+			MaxRemovedPercent: c.Guard.MaxRemovedPercent,
+			MaxAddedPercent:   c.Guard.MaxAddedPercent,
+			RequiredKeys:      c.Guard.RequiredKeys,
+			Notify:            c.Guard.Notify,
+		}
+	}
+	if c.Diff != nil {
+		differ, err := c.Diff.newDiffer()
+		if err != nil {
+			return nil, fmt.Errorf("invalid diff config: job=%s, err=%w", id, err)
+		}
+		job.Differ = differ
+	}
+	if c.ConfirmAfter < 0 {
+		return nil, fmt.Errorf("confirm_after must not be negative: job=%s", id)
+	}
+	job.ConfirmAfter = c.ConfirmAfter
+	runPolicy, err := watch.ParseRunPolicy(c.RunPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run policy: job=%s, err=%w", id, err)
+	}
+	job.RunPolicy = runPolicy
+	job.Schedule = schedule
+	if c.Adaptive != nil {
+		if schedule != "" {
+			return nil, fmt.Errorf("either of schedule or adaptive can be specified: job=%s", id)
+		}
+		adaptive, err := c.Adaptive.newAdaptiveSchedule()
+		if err != nil {
+			return nil, fmt.Errorf("invalid adaptive schedule: job=%s, err=%w", id, err)
+		}
+		job.Adaptive = adaptive
+	}
+	scheduleOptions, err := l.newScheduleOptions(&c.ScheduleOptionsConfig, l.scheduleDefaults)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule options: job=%s, err=%w", id, err)
+	}
+	job.ScheduleOptions = *scheduleOptions
+	if c.Timeout < 0 {
+		return nil, fmt.Errorf("timeout must not be negative: job=%s", id)
+	}
+	job.Timeout = seconds(c.Timeout)
+	job.AlertAfter = l.alertAfter
+	if c.AlertAfter != nil {
+		job.AlertAfter = *c.AlertAfter
+	}
+	if job.AlertAfter < 0 {
+		return nil, fmt.Errorf("alert_after must not be negative: job=%s", id)
 	}
 	return job, nil
 }
@@ -365,7 +504,17 @@ func (l *Loader) CreateSource(s *SourceConfig) (domain.Source, error) {
 		}
 		retrier = r
 	}
-	return source.NewRetrySource(src, s.EmptyAction, retrier), nil
+	if s.Timeout < 0 {
+		return nil, errors.New("source timeout must not be negative")
+	}
+	retrySource := source.NewRetrySource(src, s.EmptyAction, retrier)
+	retrySource.Timeout = seconds(s.Timeout)
+	return retrySource, nil
+}
+
+// seconds converts the seconds in the config to time.Duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
 }
 
 func (l *Loader) createSourceDOM(d *DOMSourceConfig) (domain.Source, error) {
@@ -502,12 +651,20 @@ func (l *Loader) createTransforms(t TransformsConfig, src domain.Source) (domain
 		if err != nil {
 			return nil, err
 		}
-		if transformConfig.Retry != nil {
-			retrier, err := parseRetry(transformConfig.Retry)
-			if err != nil {
-				return nil, err
+		if transformConfig.Timeout < 0 {
+			return nil, errors.New("transform timeout must not be negative")
+		}
+		if transformConfig.Retry != nil || transformConfig.Timeout > 0 {
+			var retrier *retry.Retrier
+			if transformConfig.Retry != nil {
+				retrier, err = parseRetry(transformConfig.Retry)
+				if err != nil {
+					return nil, err
+				}
 			}
-			t = transformer.NewRetryTransform(t, retrier)
+			retryTransform := transformer.NewRetryTransform(t, retrier)
+			retryTransform.Timeout = seconds(transformConfig.Timeout)
+			t = retryTransform
 		}
 		transformers = append(transformers, t)
 	}
@@ -537,6 +694,12 @@ func (l *Loader) createTransform(t *TransformConfig) (domain.Transformer, error)
 	if t.Sort != nil {
 		return transformer.NewSortTransformer(t.Sort.By), nil
 	}
+	if t.Parse != nil {
+		return l.createTransformParse(t.Parse)
+	}
+	if t.Validate != nil {
+		return l.createTransformValidate(t.Validate)
+	}
 	if t.Script != nil {
 		scr, err := t.Script.NewScript(l.ctx)
 		if err != nil {
@@ -556,3 +719,58 @@ func (l *Loader) createTransform(t *TransformConfig) (domain.Transformer, error)
 	}
 	return nil, errors.New("no transforms defined")
 }
+
+func (l *Loader) createTransformParse(p *ParseConfig) (domain.Transformer, error) {
+	fields := make(map[string]*transformer.ParseField)
+	for key, f := range p.Fields {
+		field := &transformer.ParseField{
+			Type:    transformer.ParseType(f.Type),
+			Layouts: f.Layouts,
+		}
+		if f.Timezone != nil {
+			tz, err := f.Timezone.Evaluate(l.ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate timezone template: %w", err)
+			}
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				return nil, fmt.Errorf("unknown timezone: key=%s, timezone=%s", key, tz)
+			}
+			field.Location = loc
+		}
+		fields[key] = field
+	}
+	return transformer.NewParseTransformer(fields)
+}
+
+func (l *Loader) createTransformValidate(v *ValidateConfig) (domain.Transformer, error) {
+	var schemaJSON []byte
+	if v.Schema != nil {
+		b, err := json.Marshal(v.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal inline schema: %w", err)
+		}
+		schemaJSON = b
+	} else if v.File != nil {
+		dir := l.configDirectory.childRelative("schemas")
+		file, err := dir.resolve(*v.File)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve schema file: %w", err)
+		}
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		schemaJSON, err = yaml.YAMLToJSON(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema file: file=%s, err=%w", file, err)
+		}
+	} else {
+		return nil, errors.New("either of schema or file is required for validate")
+	}
+	s, err := schema.Parse(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	return transformer.NewValidateTransformer(s), nil
+}
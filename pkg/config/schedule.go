@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain/template"
+	"github.com/uphy/watch-web/pkg/watch"
+)
+
+type (
+	// ScheduleOptionsConfig are the options of the schedule.
+	// The options at the top level are the defaults of the jobs and overridden by the job's ones.
+	ScheduleOptionsConfig struct {
+		// Timezone is the time zone name such as 'Asia/Tokyo' for the schedule, the windows and the skip dates.
+		Timezone *template.TemplateString `json:"timezone,omitempty"`
+		// ActiveWindows are the periods the job runs in.
+		ActiveWindows []ActiveWindowConfig `json:"active_windows,omitempty"`
+		// SkipDates are the dates in the format of '2006-01-02' the job doesn't run.
+		SkipDates []string `json:"skip_dates,omitempty"`
+		// Jitter delays each scheduled run randomly up to this seconds.
+		// It is applied only to '@every' and the adaptive schedules.
+		Jitter *float64 `json:"jitter,omitempty"`
+	}
+	// ActiveWindowConfig is the period of a day such as 'from: 08:00, to: 23:00, days: [mon, tue, wed, thu, fri]'.
+	ActiveWindowConfig struct {
+		// Days are the days of the week the window starts.  Empty means every day.
+		Days []string `json:"days,omitempty"`
+		// From and To are the times of day in the format of '15:04'.
+		// If To is not after From, the window ends on the next day.
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+)
+
+// newScheduleOptions creates the options of the job with the defaults for the unspecified ones.
+func (l *Loader) newScheduleOptions(job, defaults *ScheduleOptionsConfig) (*watch.ScheduleOptions, error) {
+	if defaults == nil {
+		defaults = new(ScheduleOptionsConfig)
+	}
+	timezone := job.Timezone
+	if timezone == nil {
+		timezone = defaults.Timezone
+	}
+	windows := job.ActiveWindows
+	if windows == nil {
+		windows = defaults.ActiveWindows
+	}
+	skipDates := job.SkipDates
+	if skipDates == nil {
+		skipDates = defaults.SkipDates
+	}
+	jitter := job.Jitter
+	if jitter == nil {
+		jitter = defaults.Jitter
+	}
+
+	options := &watch.ScheduleOptions{SkipDates: skipDates}
+	if timezone != nil {
+		tz, err := timezone.Evaluate(l.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate timezone template: %w", err)
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone: %s", tz)
+		}
+		options.Location = loc
+	}
+	for _, w := range windows {
+		window, err := w.activeWindow()
+		if err != nil {
+			return nil, err
+		}
+		options.Windows = append(options.Windows, window)
+	}
+	if jitter != nil {
+		if *jitter < 0 {
+			return nil, fmt.Errorf("jitter must not be negative: %v", *jitter)
+		}
+		options.Jitter = seconds(*jitter)
+	}
+	return options, nil
+}
+
+func (w *ActiveWindowConfig) activeWindow() (watch.ActiveWindow, error) {
+	var window watch.ActiveWindow
+	var err error
+	if window.Start, err = watch.ParseTimeOfDay(w.From); err != nil {
+		return window, fmt.Errorf("invalid active window: %w", err)
+	}
+	if window.End, err = watch.ParseTimeOfDay(w.To); err != nil {
+		return window, fmt.Errorf("invalid active window: %w", err)
+	}
+	for _, d := range w.Days {
+		weekday, err := watch.ParseWeekday(d)
+		if err != nil {
+			return window, fmt.Errorf("invalid active window: %w", err)
+		}
+		window.Weekdays = append(window.Weekdays, weekday)
+	}
+	return window, nil
+}
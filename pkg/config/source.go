@@ -18,6 +18,8 @@ type (
 
 		EmptyAction *source.EmptyAction `json:"empty,omitempty"`
 		Retry       interface{}         `json:"retry,omitempty"`
+		// Timeout is the timeout of each fetch in seconds including the transforms.
+		Timeout float64 `json:"timeout,omitempty"`
 	}
 	DOMSourceConfig struct {
 		URL      template.TemplateString  `json:"url"`
@@ -19,9 +19,32 @@ type (
 		Sort *struct {
 			By string `json:"by"`
 		} `json:"sort,omitempty"`
-		Script *ScriptConfig `json:"script,omitempty"`
-		Filter *ScriptConfig `json:"filter,omitempty"`
-		Debug  *bool         `json:"debug"`
-		Retry  interface{}   `json:"retry"`
+		Parse    *ParseConfig    `json:"parse,omitempty"`
+		Validate *ValidateConfig `json:"validate,omitempty"`
+		Script   *ScriptConfig   `json:"script,omitempty"`
+		Filter   *ScriptConfig   `json:"filter,omitempty"`
+		Debug    *bool           `json:"debug"`
+		Retry    interface{}     `json:"retry"`
+		// Timeout is the timeout of each transform in seconds.
+		Timeout float64 `json:"timeout,omitempty"`
+	}
+	ParseConfig struct {
+		// Fields are the parse rules for each key of the items.
+		// The original value is kept in the `_<key>_raw` key.
+		Fields map[string]ParseFieldConfig `json:"fields"`
+	}
+	ParseFieldConfig struct {
+		// Type is either of 'number' or 'date'.
+		Type string `json:"type"`
+		// Layouts are the Go time layouts for 'date'.
+		Layouts []string `json:"layouts,omitempty"`
+		// Timezone is the location name for 'date' such as 'Asia/Tokyo'.
+		Timezone *template.TemplateString `json:"timezone,omitempty"`
+	}
+	ValidateConfig struct {
+		// Schema is the inline JSON Schema.
+		Schema interface{} `json:"schema,omitempty"`
+		// File is the JSON Schema file(JSON or YAML) resolved from the 'schemas' directory.
+		File *string `json:"file,omitempty"`
 	}
 )
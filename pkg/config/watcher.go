@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watchedDirectories are the directories of the files referenced by the config file.
+var watchedDirectories = []string{"includes", "constants", "schemas"}
+
+type (
+	// Watcher detects the changes of the config file and the files referenced by it
+	// by polling their modification times.
+	Watcher struct {
+		file  string
+		dirs  []string
+		files map[string]time.Time
+	}
+)
+
+// NewWatcher creates a Watcher of the config file.
+// The current state of the files is used as the base of the changes.
+func NewWatcher(file string) *Watcher {
+	baseDirectory, _ := filepath.Split(file)
+	dirs := make([]string, len(watchedDirectories))
+	for i, dir := range watchedDirectories {
+		dirs[i] = filepath.Join(baseDirectory, dir)
+	}
+	w := &Watcher{file: file, dirs: dirs}
+	w.files = w.scan()
+	return w
+}
+
+// Changed returns true if any file is added, removed or modified since the last call.
+func (w *Watcher) Changed() bool {
+	files := w.scan()
+	changed := len(files) != len(w.files)
+	if !changed {
+		for f, modTime := range files {
+			if last, exist := w.files[f]; !exist || !last.Equal(modTime) {
+				changed = true
+				break
+			}
+		}
+	}
+	w.files = files
+	return changed
+}
+
+func (w *Watcher) scan() map[string]time.Time {
+	files := make(map[string]time.Time)
+	if info, err := os.Stat(w.file); err == nil {
+		files[w.file] = info.ModTime()
+	}
+	for _, dir := range w.dirs {
+		// the directories are optional and the unreadable files are just skipped.
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if !info.IsDir() {
+				files[path] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return files
+}
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/uphy/watch-web/pkg/domain/value"
@@ -28,35 +30,131 @@ type (
 		Last   *time.Time `json:"last,omitempty"`
 		Error  *string    `json:"error,omitempty"`
 		Count  int        `json:"count"`
+		// Violation is the guard violation which is already notified.
+		Violation *string `json:"violation,omitempty"`
+		// Pending are the updates not confirmed yet.
+		Pending []PendingUpdate `json:"pending,omitempty"`
+		// Interval is the current interval in seconds of the adaptive schedule.
+		Interval float64 `json:"interval,omitempty"`
+		// Failures is the number of the consecutive failures.
+		Failures int `json:"failures,omitempty"`
+		// FailureNotified is true if the failure is notified and the recovery is not notified yet.
+		FailureNotified bool `json:"failure_notified,omitempty"`
+	}
+	// PendingUpdate is the update which is observed but not reported yet.
+	PendingUpdate struct {
+		Key    string       `json:"key"`
+		Update value.Update `json:"update"`
+		// Count is the number of consecutive checks where the update is observed.
+		Count int       `json:"count"`
+		Since time.Time `json:"since"`
 	}
 	JobContext struct {
 		Log *logrus.Entry
+		// Timings records the durations of the stages of the check.  nil means not recorded.
+		Timings *Timings
+		// ctx carries the deadline and the cancellation of the job.  nil means context.Background().
+		ctx context.Context
+	}
+	// Timings is the durations of the stages of a check shared by the sources and the transformers.
+	Timings struct {
+		mu        sync.Mutex
+		transform time.Duration
 	}
 	Source interface {
 		Fetch(ctx *JobContext) (value.Value, error)
 	}
+	// HostSource is the Source which fetches the value from a host.
+	// The host is used to limit the concurrent requests to the same host.
+	HostSource interface {
+		Host() string
+	}
 	Transformer interface {
 		Transform(ctx *JobContext, v value.Value) (value.Value, error)
 	}
 	Action interface {
 		Run(ctx *JobContext, result *Result) error
 	}
+	// Notifier is implemented by the actions which can send a notification other than changes.
+	Notifier interface {
+		Notify(ctx *JobContext, notification *Notification) error
+	}
+
 	Store interface {
 		GetJobValue(jobID string) (string, error)
 		SetJobValue(jobID string, value string) error
 		GetJobStatus(jobID string) (*JobStatus, error)
 		SetJobStatus(jobID string, status *JobStatus) error
+		// AddJobRun adds the run to the history of the job and removes the runs out of the retention.
+		AddJobRun(jobID string, run *JobRun, retention HistoryRetention) error
+		// GetJobRuns returns the history of the job from the newest.  limit <= 0 means all.
+		GetJobRuns(jobID string, limit int) ([]JobRun, error)
 		SetTemp(key string, value string, expire time.Duration) error
 		Get(key string) (string, error)
 	}
+	// Locker is implemented by the stores which can lock across the instances sharing the store.
+	Locker interface {
+		// TryLock acquires the lock without blocking and returns false if the lock is held by another.
+		// The lock expires after ttl not to be held forever by a crashed instance.
+		// The returned token identifies the owner of the lock.
+		TryLock(key string, ttl time.Duration) (token string, locked bool, err error)
+		// Unlock releases the lock only if it is still held by the owner of the token,
+		// so that the lock taken over by another after the expiration is kept.
+		Unlock(key, token string) error
+	}
+	// Leaser is implemented by the stores which can hold a lease across the instances sharing the store.
+	// Unlike Locker, the lease is identified by the holder so that it can be renewed.
+	Leaser interface {
+		// AcquireLease acquires or renews the lease for the holder without blocking.
+		// Returns false if the lease is held by another holder.  The lease expires after ttl.
+		AcquireLease(key, holder string, ttl time.Duration) (bool, error)
+		// ReleaseLease releases the lease if it is held by the holder.
+		ReleaseLease(key, holder string) error
+	}
 	ScriptEngine interface {
 		NewScript(script string) (Script, error)
 	}
 	Script interface {
-		Evaluate(args map[string]interface{}) (interface{}, error)
+		// Evaluate evaluates the script with the args.  The evaluation is interrupted when ctx is done.
+		Evaluate(ctx context.Context, args map[string]interface{}) (interface{}, error)
 	}
 )
 
 func NewDefaultJobContext() *JobContext {
-	return &JobContext{logrus.NewEntry(logrus.New())}
+	return &JobContext{Log: logrus.NewEntry(logrus.New())}
+}
+
+// Context returns the context of the job.
+func (c *JobContext) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// WithContext returns a shallow copy of the JobContext with its context changed to ctx.
+func (c *JobContext) WithContext(ctx context.Context) *JobContext {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// AddTransform adds the duration of the transforms.  It does nothing on nil.
+func (t *Timings) AddTransform(d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.transform += d
+}
+
+// Transform returns the total duration of the transforms.
+func (t *Timings) Transform() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.transform
 }
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain/value"
+)
+
+const (
+	// DefaultHistoryMaxRuns is the default number of the runs kept in the history of each job.
+	DefaultHistoryMaxRuns = 100
+)
+
+type (
+	// JobRun is a run of the job recorded in the history.
+	JobRun struct {
+		Start time.Time `json:"start"`
+		// Duration is the duration of the run in seconds.
+		Duration float64 `json:"duration"`
+		Status   Status  `json:"status"`
+		Error    *string `json:"error,omitempty"`
+		// Items is the number of the fetched items.
+		Items int `json:"items"`
+		// Updates are the updates reported by the run.
+		Updates value.Updates `json:"updates,omitempty"`
+	}
+	// HistoryRetention is how long the runs are kept in the history.
+	HistoryRetention struct {
+		// MaxRuns is the maximum number of the runs for each job.  0 means DefaultHistoryMaxRuns.
+		MaxRuns int
+		// MaxAge is the maximum age of the runs.  0 means no limit.
+		MaxAge time.Duration
+	}
+)
+
+// Apply returns the runs kept by the retention.  The runs must be sorted from the newest.
+func (r HistoryRetention) Apply(runs []JobRun, now time.Time) []JobRun {
+	maxRuns := r.MaxRuns
+	if maxRuns <= 0 {
+		maxRuns = DefaultHistoryMaxRuns
+	}
+	if len(runs) > maxRuns {
+		runs = runs[:maxRuns]
+	}
+	if r.MaxAge > 0 {
+		expire := now.Add(-r.MaxAge)
+		for i, run := range runs {
+			if run.Start.Before(expire) {
+				return runs[:i]
+			}
+		}
+	}
+	return runs
+}
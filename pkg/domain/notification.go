@@ -0,0 +1,33 @@
+package domain
+
+const (
+	NotificationTypeGuard NotificationType = "guard"
+	// NotificationTypeFailure is sent when the job fails consecutively.
+	NotificationTypeFailure NotificationType = "failure"
+	// NotificationTypeRecovery is sent when the job succeeds after the failure notification.
+	NotificationTypeRecovery NotificationType = "recovery"
+)
+
+type (
+	NotificationType string
+	// Notification is the message about the job itself rather than the job result.
+	Notification struct {
+		Type    NotificationType
+		JobID   string
+		Label   string
+		Link    string
+		Message string
+		// Failures is the number of the consecutive failures for the failure and the recovery notifications.
+		Failures int
+	}
+)
+
+func NewNotification(info *JobInfo, notificationType NotificationType, message string) *Notification {
+	return &Notification{
+		Type:    notificationType,
+		JobID:   info.ID,
+		Label:   info.Label,
+		Link:    info.Link,
+		Message: message,
+	}
+}
@@ -9,6 +9,15 @@ type (
 		Link     string
 		Previous value.ItemList
 		Current  value.ItemList
+		// Differ is the job specific diff settings.
+		Differ *value.Differ `json:"-"`
+		// PreviousValue and CurrentValue are the typed values compared by the structural diff engine.
+		PreviousValue interface{} `json:"-"`
+		CurrentValue  interface{} `json:"-"`
+
+		updates value.Updates
+		// suppressed are the changes dropped by the change rules.
+		suppressed value.Updates
 	}
 )
 
@@ -22,6 +31,25 @@ func NewResult(info *JobInfo, previous, current value.ItemList) *Result {
 	}
 }
 
+// SetUpdates overwrites the current value and the updates.
+func (r *Result) SetUpdates(current value.ItemList, updates value.Updates) {
+	r.Current = current
+	r.updates = updates
+}
+
 func (r *Result) Diff() value.Updates {
-	return value.CompareItemList(r.Previous, r.Current)
+	if r.updates == nil {
+		if r.Differ.Structural() {
+			r.updates = r.Differ.CompareJSON(r.PreviousValue, r.CurrentValue)
+		} else {
+			r.updates, r.suppressed = r.Differ.CompareSuppressed(r.Previous, r.Current)
+		}
+	}
+	return r.updates
+}
+
+// Suppressed returns the changes which are not reported because of the change rules.
+func (r *Result) Suppressed() value.Updates {
+	r.Diff()
+	return r.suppressed
 }
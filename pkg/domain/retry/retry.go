@@ -1,6 +1,8 @@
 package retry
 
 import (
+	"context"
+	"fmt"
 	"math/rand"
 	"time"
 )
@@ -67,11 +69,17 @@ func (b *Builder) Build() *Retrier {
 }
 
 func (r *Retrier) Run(task RetryableFunc) error {
+	return r.RunContext(context.Background(), task)
+}
+
+// RunContext runs the task until it succeeds or the number of the retries is exceeded.
+// Waiting for the next retry is aborted when ctx is done.
+func (r *Retrier) RunContext(ctx context.Context, task RetryableFunc) error {
 	var err error
-	ctx := new(RetryContext)
+	retryContext := new(RetryContext)
 	exponentialBackoff := r.initialInterval
 	for {
-		err = task(ctx)
+		err = task(retryContext)
 		if err == nil {
 			break
 		}
@@ -84,11 +92,17 @@ func (r *Retrier) Run(task RetryableFunc) error {
 		} else {
 			exponentialBackoff *= r.multiplier
 		}
-		time.Sleep(time.Duration(wait))
+		timer := time.NewTimer(time.Duration(wait))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%v: %w", err, ctx.Err())
+		}
 
-		ctx.Retried++
+		retryContext.Retried++
 
-		if ctx.Retried >= r.retry {
+		if retryContext.Retried >= r.retry {
 			break
 		}
 	}
@@ -1,8 +1,10 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestRetrier_Run(t *testing.T) {
@@ -86,3 +88,40 @@ func TestRetrier_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrier_RunContext(t *testing.T) {
+	r := NewBuilder(3).InitialInterval(10).Build()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	called := 0
+	start := time.Now()
+	err := r.RunContext(ctx, func(ctx *RetryContext) error {
+		called++
+		return errors.New("error")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Retrier.RunContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if called != 1 {
+		t.Errorf("called = %d, want 1", called)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waiting for the retry should be aborted: elapsed=%v", elapsed)
+	}
+}
+
+func TestRetrier_RunCount(t *testing.T) {
+	// retry is the number of the attempts in total
+	r := NewBuilder(3).InitialInterval(0.001).Build()
+	called := 0
+	err := r.Run(func(ctx *RetryContext) error {
+		called++
+		return errors.New("error")
+	})
+	if err == nil {
+		t.Error("Retrier.Run() should fail")
+	}
+	if called != 3 {
+		t.Errorf("called = %d, want 3", called)
+	}
+}
@@ -0,0 +1,356 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	// Schema is the subset of the JSON Schema.
+	// Supported keywords are type, enum, const, properties, required, additionalProperties,
+	// items, minItems, maxItems, uniqueItems, pattern, minLength, maxLength, minimum, maximum,
+	// exclusiveMinimum, exclusiveMaximum, allOf, anyOf, oneOf and not.
+	Schema struct {
+		Type                 typeList           `json:"type,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+		Const                interface{}        `json:"const,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		AdditionalProperties *additional        `json:"additionalProperties,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		MinItems             *int               `json:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty"`
+		UniqueItems          bool               `json:"uniqueItems,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		MinLength            *int               `json:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+		AllOf                []*Schema          `json:"allOf,omitempty"`
+		AnyOf                []*Schema          `json:"anyOf,omitempty"`
+		OneOf                []*Schema          `json:"oneOf,omitempty"`
+		Not                  *Schema            `json:"not,omitempty"`
+
+		pattern *regexp.Regexp
+	}
+	typeList   []string
+	additional struct {
+		allowed bool
+		schema  *Schema
+	}
+	// ValidationError is the violation of the schema at the path.
+	ValidationError struct {
+		// Path is the JSON Pointer of the invalid value.
+		Path    string
+		Message string
+	}
+	// ValidationErrors is the report of the validation.
+	ValidationErrors []ValidationError
+)
+
+// maxReportedErrors is the max number of errors included in the error message.
+const maxReportedErrors = 10
+
+// Parse parses the JSON Schema.
+func Parse(b []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		p, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: pattern=%s, err=%w", s.Pattern, err)
+		}
+		s.pattern = p
+	}
+	children := []*Schema{s.Items, s.Not}
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	if s.AdditionalProperties != nil {
+		children = append(children, s.AdditionalProperties.schema)
+	}
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	for _, c := range children {
+		if err := c.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate validates the value.
+// The value should be the one decoded by encoding/json.
+// Returns nil if the value is valid.
+func (s *Schema) Validate(v interface{}) ValidationErrors {
+	errs := make(ValidationErrors, 0)
+	s.validate("", v, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (s *Schema) validate(path string, v interface{}, errs *ValidationErrors) {
+	report := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{pathOrRoot(path), fmt.Sprintf(format, args...)})
+	}
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		report("expected %s but got %s", strings.Join(s.Type, " or "), typeOf(v))
+		return
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if equal(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report("value %s is not one of %s", format(v), format(s.Enum))
+		}
+	}
+	if s.Const != nil && !equal(s.Const, v) {
+		report("value %s is not %s", format(v), format(s.Const))
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, exist := value[key]; !exist {
+				report("required property '%s' is missing", key)
+			}
+		}
+		for _, key := range sortedKeys(value) {
+			childPath := path + "/" + escapePointer(key)
+			if p, exist := s.Properties[key]; exist {
+				p.validate(childPath, value[key], errs)
+				continue
+			}
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if !s.AdditionalProperties.allowed {
+				report("additional property '%s' is not allowed", key)
+			} else if s.AdditionalProperties.schema != nil {
+				s.AdditionalProperties.schema.validate(childPath, value[key], errs)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			report("expected at least %d items but got %d", *s.MinItems, len(value))
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			report("expected at most %d items but got %d", *s.MaxItems, len(value))
+		}
+		if s.UniqueItems {
+			for i := 0; i < len(value); i++ {
+				for j := i + 1; j < len(value); j++ {
+					if equal(value[i], value[j]) {
+						report("items at %d and %d are not unique", i, j)
+					}
+				}
+			}
+		}
+		if s.Items != nil {
+			for i, elm := range value {
+				s.Items.validate(fmt.Sprintf("%s/%d", path, i), elm, errs)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if s.MinLength != nil && length < *s.MinLength {
+			report("expected at least %d characters but got %d", *s.MinLength, length)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			report("expected at most %d characters but got %d", *s.MaxLength, length)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(value) {
+			report("value %s does not match pattern %s", format(value), s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && value < *s.Minimum {
+			report("value %v is less than minimum %v", value, *s.Minimum)
+		}
+		if s.Maximum != nil && value > *s.Maximum {
+			report("value %v is greater than maximum %v", value, *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && value <= *s.ExclusiveMinimum {
+			report("value %v is not greater than %v", value, *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && value >= *s.ExclusiveMaximum {
+			report("value %v is not less than %v", value, *s.ExclusiveMaximum)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(path, v, errs)
+	}
+	if len(s.AnyOf) > 0 && s.countValid(s.AnyOf, v) == 0 {
+		report("value does not match any of the schemas in anyOf")
+	}
+	if len(s.OneOf) > 0 {
+		if n := s.countValid(s.OneOf, v); n != 1 {
+			report("value should match exactly one schema in oneOf but matched %d", n)
+		}
+	}
+	if s.Not != nil && len(s.Not.Validate(v)) == 0 {
+		report("value should not match the schema in not")
+	}
+}
+
+func (s *Schema) countValid(schemas []*Schema, v interface{}) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.Validate(v)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (t *typeList) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = typeList{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return fmt.Errorf("type should be a string or an array of string: %s", string(b))
+	}
+	*t = multiple
+	return nil
+}
+
+func (t typeList) matches(v interface{}) bool {
+	actual := typeOf(v)
+	for _, expected := range t {
+		if expected == actual {
+			return true
+		}
+		if expected == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *additional) UnmarshalJSON(b []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(b, &allowed); err == nil {
+		a.allowed = allowed
+		return nil
+	}
+	var s Schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	a.allowed = true
+	a.schema = &s
+	return nil
+}
+
+func (a *additional) MarshalJSON() ([]byte, error) {
+	if a.schema != nil {
+		return json.Marshal(a.schema)
+	}
+	return json.Marshal(a.allowed)
+}
+
+func typeOf(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func equal(v1, v2 interface{}) bool {
+	return reflect.DeepEqual(v1, v2)
+}
+
+func format(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := string(b)
+	if runes := []rune(s); len(runes) > 50 {
+		return string(runes[:50]) + "..."
+	}
+	return s
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapePointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func (e ValidationError) String() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Error returns the readable report of the validation errors.
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0)
+	for i, err := range e {
+		if i >= maxReportedErrors {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(e)-maxReportedErrors))
+			break
+		}
+		lines = append(lines, "- "+err.String())
+	}
+	return fmt.Sprintf("%d schema violation(s):\n%s", len(e), strings.Join(lines, "\n"))
+}
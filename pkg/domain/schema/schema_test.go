@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const itemsSchema = `{
+	"type": "array",
+	"minItems": 1,
+	"maxItems": 3,
+	"items": {
+		"type": "object",
+		"required": ["id", "price"],
+		"properties": {
+			"id": {"type": "string", "pattern": "^[0-9]+$"},
+			"price": {"type": "number", "minimum": 0},
+			"state": {"enum": ["AVAILABLE", "SOLD_OUT"]}
+		}
+	}
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  ValidationErrors
+	}{
+		{
+			name:  "valid",
+			value: `[{"id":"001","price":100,"state":"AVAILABLE"},{"id":"002","price":1.5}]`,
+		},
+		{
+			name:  "not an array",
+			value: `{"id":"001"}`,
+			want:  ValidationErrors{{"/", "expected array but got object"}},
+		},
+		{
+			name:  "empty",
+			value: `[]`,
+			want:  ValidationErrors{{"/", "expected at least 1 items but got 0"}},
+		},
+		{
+			name:  "broken items",
+			value: `[{"id":"abc","price":"100"},{"price":-1,"state":"UNKNOWN"}]`,
+			want: ValidationErrors{
+				{"/0/id", `value "abc" does not match pattern ^[0-9]+$`},
+				{"/0/price", "expected number but got string"},
+				{"/1", "required property 'id' is missing"},
+				{"/1/price", "value -1 is less than minimum 0"},
+				{"/1/state", `value "UNKNOWN" is not one of ["AVAILABLE","SOLD_OUT"]`},
+			},
+		},
+	}
+	s, err := Parse([]byte(itemsSchema))
+	if err != nil {
+		t.Fatal("failed to parse schema:", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := json.Unmarshal([]byte(tt.value), &v); err != nil {
+				t.Fatal("failed to unmarshal value:", err)
+			}
+			if got := s.Validate(v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Schema.Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse([]byte(`{"type":"string","pattern":"("}`)); err == nil {
+		t.Error("invalid pattern should be an error")
+	}
+	s, err := Parse([]byte(`{"type":["string","null"],"additionalProperties":false}`))
+	if err != nil {
+		t.Fatal("failed to parse schema:", err)
+	}
+	if errs := s.Validate(nil); errs != nil {
+		t.Errorf("null should be valid: %v", errs)
+	}
+}
@@ -1,6 +1,7 @@
 package script
 
 import (
+	"context"
 	"fmt"
 	"github.com/uphy/watch-web/pkg/domain/template"
 	"regexp"
@@ -62,14 +63,14 @@ func (a *AnkoScriptEngine) NewScript(script string) (domain.Script, error) {
 	return &AnkoScript{e, script}, nil
 }
 
-func (s *AnkoScript) Evaluate(args map[string]interface{}) (interface{}, error) {
+func (s *AnkoScript) Evaluate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	for k, v := range args {
 		if err := s.e.Define(k, v); err != nil {
 			return nil, fmt.Errorf("failed to set source to anko script engine:%w", err)
 		}
 	}
 
-	result, err := vm.Execute(s.e, nil, s.script)
+	result, err := vm.ExecuteContext(ctx, s.e, nil, s.script)
 	if err != nil {
 		if e, ok := err.(*vm.Error); ok {
 			return nil, fmt.Errorf("failed to execute script: line=%d, col=%d, err=%w", e.Pos.Line, e.Pos.Column, e)
@@ -1,12 +1,17 @@
 package script
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/robertkrimen/otto"
 	"github.com/uphy/watch-web/pkg/domain"
 )
 
+// errInterrupted is the panic value to interrupt the script.
+var errInterrupted = errors.New("interrupted")
+
 type (
 	JavaScriptEngine struct {
 		vm *otto.Otto
@@ -29,16 +34,38 @@ func (e *JavaScriptEngine) NewScript(script string) (domain.Script, error) {
 	return &JavaScript{e.vm, s}, nil
 }
 
-func (s *JavaScript) Evaluate(args map[string]interface{}) (interface{}, error) {
+func (s *JavaScript) Evaluate(ctx context.Context, args map[string]interface{}) (result interface{}, err error) {
 	vm := s.vm.Copy()
 	for k, v := range args {
 		vm.Set(k, v)
 	}
-	result, err := vm.Run(s.script)
+	// interrupt the script when ctx is done.
+	vm.Interrupt = make(chan func(), 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			vm.Interrupt <- func() {
+				panic(errInterrupted)
+			}
+		case <-done:
+		}
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errInterrupted {
+				panic(r)
+			}
+			result, err = nil, fmt.Errorf("script interrupted: %w", ctx.Err())
+		}
+	}()
+
+	v, err := vm.Run(s.script)
 	if err != nil {
 		return nil, err
 	}
-	exported, err := result.Export()
+	exported, err := v.Export()
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,12 @@
 package script
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/uphy/watch-web/pkg/domain"
 	"github.com/uphy/watch-web/pkg/domain/value"
 )
 
@@ -88,7 +91,7 @@ func TestJavaScript_Evaluate(t *testing.T) {
 			t.Errorf("failed to parse script: %v", err)
 		}
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := script.Evaluate(tt.args)
+			got, err := script.Evaluate(context.Background(), tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("JavaScript.Evaluate() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -99,3 +102,27 @@ func TestJavaScript_Evaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestJavaScript_EvaluateInterrupt(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine domain.ScriptEngine
+		script string
+	}{
+		{"javascript", NewJavaScriptEngine(), "while(true){}"},
+		{"anko", NewAnkoScriptEngine(), "for {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := tt.engine.NewScript(tt.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if _, err := script.Evaluate(ctx, nil); err == nil {
+				t.Error("Evaluate() should be interrupted")
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package script
 
 import (
+	"context"
+
 	"github.com/uphy/watch-web/pkg/domain"
 	"github.com/uphy/watch-web/pkg/domain/template"
 )
@@ -27,7 +29,7 @@ func (t *TemplateScriptEngine) NewScript(script string) (domain.Script, error) {
 	return &TemplateScript{t.ctx, tmpl}, nil
 }
 
-func (s *TemplateScript) Evaluate(args map[string]interface{}) (interface{}, error) {
+func (s *TemplateScript) Evaluate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	s.ctx.PushScope()
 	defer s.ctx.PopScope()
 	for k, v := range args {
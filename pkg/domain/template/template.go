@@ -23,11 +23,12 @@ var funcs = map[string]interface{}{
 	"env": func(name string) string {
 		return os.Getenv(name)
 	},
-	"default": func(defaultValue string, value string) string {
-		if value == "" {
+	"default": func(defaultValue string, v interface{}) string {
+		s := value.FormatItemValue(v)
+		if s == "" {
 			return defaultValue
 		}
-		return value
+		return s
 	},
 	"sliceOf": func(values ...string) []string {
 		return values
@@ -50,8 +51,8 @@ var funcs = map[string]interface{}{
 		}
 		return v.Interface(), nil
 	},
-	"trim": func(s string) string {
-		return strings.Trim(s, " 　\t\r\n")
+	"trim": func(v interface{}) string {
+		return strings.Trim(value.FormatItemValue(v), " 　\t\r\n")
 	},
 	"jsonFormat": func(jsonString string) (string, error) {
 		var v interface{}
@@ -61,7 +62,8 @@ var funcs = map[string]interface{}{
 		b, _ := json.MarshalIndent(v, "", "   ")
 		return string(b), nil
 	},
-	"truncate": func(length int, s string) string {
+	"truncate": func(length int, v interface{}) string {
+		s := value.FormatItemValue(v)
 		runes := []rune(s)
 		if len(runes) > length {
 			return string(runes[0:length]) + "..."
@@ -93,7 +95,46 @@ var funcs = map[string]interface{}{
 		n := int64(epochMillis) * 1000000
 		return time.Unix(0, n).Format("2006/01/02 15:04")
 	},
-	"escape": func(s string) string {
+	// number converts the typed or string value to float64 for the arithmetic and the comparison.
+	"number": toNumber,
+	"add": func(a, b interface{}) (float64, error) {
+		return calculate(a, b, func(x, y float64) float64 { return x + y })
+	},
+	"sub": func(a, b interface{}) (float64, error) {
+		return calculate(a, b, func(x, y float64) float64 { return x - y })
+	},
+	"mul": func(a, b interface{}) (float64, error) {
+		return calculate(a, b, func(x, y float64) float64 { return x * y })
+	},
+	"div": func(a, b interface{}) (float64, error) {
+		return calculate(a, b, func(x, y float64) float64 { return x / y })
+	},
+	"formatNumber": func(v interface{}) (string, error) {
+		n, err := toNumber(v)
+		if err != nil {
+			return "", err
+		}
+		return value.FormatItemValue(n), nil
+	},
+	"formatTime": func(layout string, v interface{}) (string, error) {
+		switch t := v.(type) {
+		case time.Time:
+			return t.Format(layout), nil
+		case string:
+			parsed, err := time.Parse(time.RFC3339Nano, t)
+			if err != nil {
+				return "", fmt.Errorf("not a time: %s", t)
+			}
+			return parsed.Format(layout), nil
+		default:
+			return "", fmt.Errorf("not a time: %v", v)
+		}
+	},
+	"textDiff": func(old, new string) value.TextDiffs {
+		return value.DiffText(old, new)
+	},
+	"escape": func(v interface{}) string {
+		s := value.FormatItemValue(v)
 		s = strings.ReplaceAll(s, "\n", "\\n")
 		s = strings.ReplaceAll(s, "\"", "”")
 		s = strings.ReplaceAll(s, "<", "＜")
@@ -102,6 +143,26 @@ var funcs = map[string]interface{}{
 	},
 }
 
+func toNumber(v interface{}) (float64, error) {
+	n, ok := value.ToNumber(v)
+	if !ok {
+		return 0, fmt.Errorf("not a number: %v", v)
+	}
+	return n, nil
+}
+
+func calculate(a, b interface{}, op func(x, y float64) float64) (float64, error) {
+	x, err := toNumber(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toNumber(b)
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
+
 func Parse(s string) (*Template, error) {
 	tmpl, err := template.New("template-string").Funcs(funcs).Parse(s)
 	if err != nil {
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
-	"strings"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
@@ -18,43 +17,72 @@ type (
 
 // CompareItemList computes the differences between ItemLists.
 func CompareItemList(list1, list2 ItemList) Updates {
+	return compareItemList(list1, list2, nil, nil)
+}
+
+// compareItemList computes the differences between ItemLists.
+// Items are compared after applying the ignore rule, but the updates have the original items.
+// Items which exist in both lists at the different positions are reported as moved instead of removed and added.
+func compareItemList(list1, list2 ItemList, ignore *IgnoreRule, move *MoveOption) Updates {
 	list1 = list1.forCompare()
 	list2 = list2.forCompare()
+	compare1 := ignore.apply(list1)
+	compare2 := ignore.apply(list2)
 
 	// Get ids and compare only id
-	idToItem1 := make(map[string]Item)
-	ids1 := make([]string, len(list1))
-	for i, item := range list1 {
+	idToIndex1 := make(map[string]int)
+	ids1 := make([]string, len(compare1))
+	for i, item := range compare1 {
 		id := item.ID()
-		idToItem1[id] = item
+		idToIndex1[id] = i
 		ids1[i] = id
 	}
-	idToItem2 := make(map[string]Item)
-	ids2 := make([]string, len(list2))
-	for i, item := range list2 {
+	idToIndex2 := make(map[string]int)
+	ids2 := make([]string, len(compare2))
+	for i, item := range compare2 {
 		id := item.ID()
-		idToItem2[id] = item
+		idToIndex2[id] = i
 		ids2[i] = id
 	}
 
 	// Compare IDs
-	ids1s := strings.Join(ids1, "\n")
-	ids2s := strings.Join(ids2, "\n")
-	diffs := diff(ids1s, ids2s)
+	diffs := diff(ids1, ids2)
+	deleted := make(map[string]bool)
+	for _, d := range diffs {
+		if d.diffType == diffmatchpatch.DiffDelete {
+			deleted[d.text] = true
+		}
+	}
 	updates := make(Updates, 0)
+	compare := func(id string) {
+		index1 := idToIndex1[id]
+		index2 := idToIndex2[id]
+		changed := compareItem(compare1[index1], compare2[index2])
+		if changed != nil {
+			updates = append(updates, *updateChange(changed.restore(list1[index1], list2[index2])))
+		}
+	}
 	for _, d := range diffs {
 		switch d.diffType {
 		case diffmatchpatch.DiffInsert:
-			updates = append(updates, *updateAdd(idToItem2[d.text]))
+			if deleted[d.text] {
+				// moved
+				from := idToIndex1[d.text]
+				to := idToIndex2[d.text]
+				if move.report(from, to) {
+					updates = append(updates, *updateMove(&ItemMove{list2[to], from, to}))
+				}
+				compare(d.text)
+				continue
+			}
+			updates = append(updates, *updateAdd(list2[idToIndex2[d.text]]))
 		case diffmatchpatch.DiffDelete:
-			updates = append(updates, *updateRemove(idToItem1[d.text]))
-		case diffmatchpatch.DiffEqual:
-			item1 := idToItem1[d.text]
-			item2 := idToItem2[d.text]
-			changed := compareItem(item1, item2)
-			if changed != nil {
-				updates = append(updates, *updateChange(changed))
+			if _, moved := idToIndex2[d.text]; moved {
+				continue
 			}
+			updates = append(updates, *updateRemove(list1[idToIndex1[d.text]]))
+		case diffmatchpatch.DiffEqual:
+			compare(d.text)
 		default:
 			continue
 		}
@@ -63,6 +91,28 @@ func CompareItemList(list1, list2 ItemList) Updates {
 	return updates
 }
 
+// restore returns the ItemChange which has the values of the original items.
+func (u *ItemChange) restore(item1, item2 Item) *ItemChange {
+	restored := &ItemChange{
+		Item:        item2,
+		AddedKeys:   make(map[string]string, len(u.AddedKeys)),
+		RemovedKeys: make(map[string]string, len(u.RemovedKeys)),
+		ChangedKeys: make(map[string]ItemValueChange, len(u.ChangedKeys)),
+		previousID:  item1.ID(),
+	}
+	// the numbers stored by the older versions are shown in the same format as the new ones
+	for k := range u.AddedKeys {
+		restored.AddedKeys[k] = compareString(item2[k])
+	}
+	for k := range u.RemovedKeys {
+		restored.RemovedKeys[k] = compareString(item1[k])
+	}
+	for k := range u.ChangedKeys {
+		restored.ChangedKeys[k] = ItemValueChange{Old: compareString(item1[k]), New: compareString(item2[k])}
+	}
+	return restored
+}
+
 // diffItem compare 2 items.
 // return null if given items are exactly same.
 func compareItem(item1, item2 Item) *ItemChange {
@@ -85,21 +135,19 @@ func compareItem(item1, item2 Item) *ItemChange {
 	sort.Strings(keys2)
 
 	// Compare them
-	keys1s := strings.Join(keys1, "\n")
-	keys2s := strings.Join(keys2, "\n")
-	diffs := diff(keys1s, keys2s)
+	diffs := diff(keys1, keys2)
 	addedKeys := make(map[string]string, 0)
 	removedKeys := make(map[string]string, 0)
 	changedKeys := make(map[string]ItemValueChange, 0)
 	for _, d := range diffs {
 		switch d.diffType {
 		case diffmatchpatch.DiffInsert:
-			addedKeys[d.text] = item2[d.text]
+			addedKeys[d.text] = item2.GetString(d.text)
 		case diffmatchpatch.DiffDelete:
-			removedKeys[d.text] = item1[d.text]
+			removedKeys[d.text] = item1.GetString(d.text)
 		case diffmatchpatch.DiffEqual:
-			v1 := item1[d.text]
-			v2 := item2[d.text]
+			v1 := item1.GetString(d.text)
+			v2 := item2.GetString(d.text)
 			if v1 != v2 {
 				changedKeys[d.text] = ItemValueChange{
 					Old: v1,
@@ -113,31 +161,7 @@ func compareItem(item1, item2 Item) *ItemChange {
 	if len(addedKeys) == 0 && len(removedKeys) == 0 && len(changedKeys) == 0 {
 		return nil
 	}
-	return &ItemChange{item2, addedKeys, removedKeys, changedKeys}
-}
-
-func diff(v1, v2 string) []diffItem {
-	v1 = strings.Trim(v1, " \t\n")
-	if len(v1) > 0 {
-		v1 = v1 + "\n"
-	}
-	v2 = strings.Trim(v2, " \t\n")
-	if len(v2) > 0 {
-		v2 = v2 + "\n"
-	}
-
-	d := diffmatchpatch.New()
-	a, b, c := d.DiffLinesToChars(v1, v2)
-	diffs := d.DiffMain(a, b, false)
-	res := make([]diffItem, 0)
-	for _, diff := range d.DiffCharsToLines(diffs, c) {
-		text := strings.Trim(diff.Text, " \t\n")
-		splitted := strings.Split(text, "\n")
-		for _, s := range splitted {
-			res = append(res, diffItem{diff.Type, s})
-		}
-	}
-	return res
+	return &ItemChange{Item: item2, AddedKeys: addedKeys, RemovedKeys: removedKeys, ChangedKeys: changedKeys}
 }
 
 func (u *ItemChange) String() string {
@@ -2,8 +2,13 @@ package value
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/rand"
 	"reflect"
+	"regexp"
 	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 func TestUnmarshalJSON(t *testing.T) {
@@ -29,6 +34,7 @@ func TestUnmarshalJSON(t *testing.T) {
 		),
 		*updateRemove(Item{ItemKeyID: "item2", "c": "1", "d": "3"}),
 		*updateAdd(Item{ItemKeyID: "item3", "e": "4", "f": "5"}),
+		*updateMove(&ItemMove{Item: Item{ItemKeyID: "item4"}, From: 3, To: 0}),
 	}
 	b, _ := json.MarshalIndent(updates, "", "   ")
 	var v Updates
@@ -85,6 +91,7 @@ func TestCompareItemList(t *testing.T) {
 						RemovedKeys: map[string]string{
 							"remove": "2",
 						},
+						previousID: "item1",
 					},
 				),
 				*updateRemove(Item{ItemKeyID: "item2", "c": "1", "d": "3", "label": "", "link": "", "summary": ""}),
@@ -121,3 +128,339 @@ func toJSON(v interface{}) string {
 	b, _ := json.MarshalIndent(v, "", "   ")
 	return string(b)
 }
+
+func TestDiffer_CompareIgnore(t *testing.T) {
+	ignore, err := NewIgnoreRule([]string{"updated"}, []string{"session*"}, []ValueMask{
+		{Pattern: regexp.MustCompile(`\d+ views`), Replacement: "<masked>"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	differ := &Differ{Ignore: ignore}
+	list1 := ItemList{
+		Item{"title": "A", "views": "10 views", "updated": "10:00", "session_id": "abc"},
+		Item{ItemKeyID: "b", "title": "B", "views": "20 views"},
+	}
+	list2 := ItemList{
+		Item{"title": "A", "views": "11 views", "updated": "10:05", "session_id": "def"},
+		Item{ItemKeyID: "b", "title": "BB", "views": "21 views"},
+	}
+	want := Updates{
+		*updateChange(&ItemChange{
+			Item:        Item{ItemKeyID: "b", "title": "BB", "views": "21 views", "label": "", "link": "", "summary": ""},
+			AddedKeys:   map[string]string{},
+			RemovedKeys: map[string]string{},
+			ChangedKeys: map[string]ItemValueChange{"title": {Old: "B", New: "BB"}},
+			previousID:  "b",
+		}),
+	}
+	if got := differ.Compare(list1, list2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Differ.Compare() = %v, want %v", toJSON(got), toJSON(want))
+	}
+}
+
+func TestDiffer_CompareMove(t *testing.T) {
+	list1 := ItemList{
+		Item{ItemKeyID: "1"},
+		Item{ItemKeyID: "2"},
+		Item{ItemKeyID: "3"},
+		Item{ItemKeyID: "4", "rank": "4th"},
+	}
+	list2 := ItemList{
+		Item{ItemKeyID: "4", "rank": "1st"},
+		Item{ItemKeyID: "1"},
+		Item{ItemKeyID: "3"},
+		Item{ItemKeyID: "2"},
+	}
+	tests := []struct {
+		name string
+		move *MoveOption
+		want []UpdateType
+	}{
+		{
+			name: "default",
+			want: []UpdateType{UpdateTypeChange},
+		},
+		{
+			name: "report",
+			move: &MoveOption{Mode: MoveModeReport},
+			want: []UpdateType{UpdateTypeMove, UpdateTypeChange, UpdateTypeMove},
+		},
+		{
+			name: "ignore",
+			move: &MoveOption{Mode: MoveModeIgnore},
+			want: []UpdateType{UpdateTypeChange},
+		},
+		{
+			name: "min distance",
+			move: &MoveOption{Mode: MoveModeReport, MinDistance: 2},
+			want: []UpdateType{UpdateTypeMove, UpdateTypeChange},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Differ{Move: tt.move}).Compare(list1, list2)
+			types := make([]UpdateType, 0)
+			for _, u := range got {
+				types = append(types, u.Type)
+			}
+			if !reflect.DeepEqual(types, tt.want) {
+				t.Errorf("Differ.Compare() = %v, want %v", toJSON(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffer_CompareMatch(t *testing.T) {
+	list1 := ItemList{
+		Item{"label": "Tokyo Tower Tour", "price": "3000"},
+		Item{"label": "Kyoto Temple Walk", "price": "2000"},
+		Item{ItemKeyID: "x", "label": "Osaka Castle", "price": "1000"},
+	}
+	list2 := ItemList{
+		Item{"label": "Tokyo Tower Tours", "price": "3000"},
+		Item{"label": "Hokkaido Snow Festival", "price": "5000"},
+		Item{ItemKeyID: "y", "label": "Osaka Castle", "price": "1000"},
+	}
+	tests := []struct {
+		name    string
+		matcher *SimilarityMatcher
+		want    []UpdateType
+	}{
+		{
+			name: "disabled",
+			want: []UpdateType{UpdateTypeRemove, UpdateTypeRemove, UpdateTypeRemove, UpdateTypeAdd, UpdateTypeAdd, UpdateTypeAdd},
+		},
+		{
+			name:    "default threshold",
+			matcher: &SimilarityMatcher{},
+			want:    []UpdateType{UpdateTypeRemove, UpdateTypeRemove, UpdateTypeChange, UpdateTypeAdd, UpdateTypeAdd},
+		},
+		{
+			name:    "fields",
+			matcher: &SimilarityMatcher{Fields: []string{"price"}, Threshold: 1},
+			want:    []UpdateType{UpdateTypeRemove, UpdateTypeRemove, UpdateTypeChange, UpdateTypeAdd, UpdateTypeAdd},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Differ{Matcher: tt.matcher}).Compare(list1, list2)
+			types := make([]UpdateType, 0)
+			for _, u := range got {
+				types = append(types, u.Type)
+				if u.Type == UpdateTypeChange {
+					want := ItemValueChange{Old: "Tokyo Tower Tour", New: "Tokyo Tower Tours"}
+					if c := u.Change.ChangedKeys["label"]; c != want {
+						t.Errorf("ItemChange.ChangedKeys[label] = %v, want %v", c, want)
+					}
+				}
+			}
+			if !reflect.DeepEqual(types, tt.want) {
+				t.Errorf("Differ.Compare() = %v, want %v", toJSON(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarityMatcher_Similarity(t *testing.T) {
+	m := &SimilarityMatcher{}
+	tests := []struct {
+		name         string
+		item1, item2 Item
+		want         float64
+	}{
+		{"same", Item{"a": "abcd"}, Item{"a": "abcd"}, 1},
+		{"one char", Item{"a": "abcd"}, Item{"a": "abce"}, 0.75},
+		{"multibyte", Item{"a": "在庫あり"}, Item{"a": "在庫なし"}, 0.5},
+		{"missing key", Item{"a": "abcd", "b": "x"}, Item{"a": "abcd"}, 0.5},
+		{"empty keys", Item{"a": "abcd", "b": ""}, Item{"a": "abcd"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Similarity(tt.item1, tt.item2); got != tt.want {
+				t.Errorf("SimilarityMatcher.Similarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevertUpdates(t *testing.T) {
+	previous := ItemList{
+		Item{ItemKeyID: "1"},
+		Item{ItemKeyID: "2", "price": "100"},
+		Item{ItemKeyID: "3"},
+	}
+	current := ItemList{
+		Item{ItemKeyID: "2", "price": "200"},
+		Item{ItemKeyID: "3"},
+		Item{ItemKeyID: "4"},
+	}
+	updates := CompareItemList(previous, current)
+	reverted := RevertUpdates(previous, current, updates)
+	if got := CompareItemList(previous, reverted); len(got) != 0 {
+		t.Errorf("RevertUpdates() = %v, want no updates from previous", toJSON(got))
+	}
+}
+
+func TestRevertUpdates_KeepOriginal(t *testing.T) {
+	previous := ItemList{
+		Item{ItemKeyID: "1", "price": 100., "_internal": "a"},
+		Item{"title": "no id", "count": 1., "_internal": "b"},
+	}
+	current := ItemList{
+		Item{ItemKeyID: "1", "price": 200., "_internal": "c"},
+		Item{"title": "no id", "count": 2., "_internal": "d"},
+	}
+	differ := &Differ{Matcher: &SimilarityMatcher{Threshold: 0.5}}
+	updates := differ.Compare(previous, current)
+	if len(updates) != 2 {
+		t.Fatalf("unexpected updates: %v", toJSON(updates))
+	}
+	if reverted := RevertUpdates(previous, current, updates); !reflect.DeepEqual(reverted, previous) {
+		t.Errorf("RevertUpdates() = %v, want %v", toJSON(reverted), toJSON(previous))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []diffItem
+	}{
+		{
+			name: "deletions before insertions",
+			a:    []string{"a", "b", "c"},
+			b:    []string{"a", "x", "y", "c"},
+			want: []diffItem{
+				{diffmatchpatch.DiffEqual, "a"},
+				{diffmatchpatch.DiffDelete, "b"},
+				{diffmatchpatch.DiffInsert, "x"},
+				{diffmatchpatch.DiffInsert, "y"},
+				{diffmatchpatch.DiffEqual, "c"},
+			},
+		},
+		{
+			name: "newline in elements",
+			a:    []string{"a\nb", "c"},
+			b:    []string{"c", "a\nb"},
+			want: []diffItem{
+				{diffmatchpatch.DiffDelete, "a\nb"},
+				{diffmatchpatch.DiffEqual, "c"},
+				{diffmatchpatch.DiffInsert, "a\nb"},
+			},
+		},
+		{
+			name: "empty",
+			a:    []string{},
+			b:    []string{"", " "},
+			want: []diffItem{
+				{diffmatchpatch.DiffInsert, ""},
+				{diffmatchpatch.DiffInsert, " "},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiff_Random checks that the diff reproduces both slices with the minimum edits.
+func TestDiff_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomSlice := func() []string {
+		s := make([]string, r.Intn(30))
+		for i := range s {
+			s[i] = fmt.Sprint(r.Intn(6))
+		}
+		return s
+	}
+	for i := 0; i < 500; i++ {
+		a, b := randomSlice(), randomSlice()
+		gotA, gotB := make([]string, 0), make([]string, 0)
+		equals := 0
+		for _, d := range diff(a, b) {
+			switch d.diffType {
+			case diffmatchpatch.DiffDelete:
+				gotA = append(gotA, d.text)
+			case diffmatchpatch.DiffInsert:
+				gotB = append(gotB, d.text)
+			default:
+				gotA = append(gotA, d.text)
+				gotB = append(gotB, d.text)
+				equals++
+			}
+		}
+		if !reflect.DeepEqual(gotA, a) || !reflect.DeepEqual(gotB, b) {
+			t.Fatalf("diff(%v, %v) doesn't reproduce the slices: %v, %v", a, b, gotA, gotB)
+		}
+		if lcs := lcsLength(a, b); equals != lcs {
+			t.Fatalf("diff(%v, %v) has %d equals, want %d", a, b, equals, lcs)
+		}
+	}
+}
+
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else if dp[i-1][j] > dp[i][j-1] {
+				dp[i][j] = dp[i-1][j]
+			} else {
+				dp[i][j] = dp[i][j-1]
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
+
+func benchmarkItemLists(size int) (ItemList, ItemList) {
+	r := rand.New(rand.NewSource(1))
+	list1 := make(ItemList, size)
+	list2 := make(ItemList, 0, size)
+	for i := range list1 {
+		list1[i] = Item{ItemKeyID: fmt.Sprint(i), "label": fmt.Sprint("item ", i)}
+	}
+	// change 1% of the items, remove 1% and add 1%
+	for i, item := range list1 {
+		switch r.Intn(100) {
+		case 0:
+			continue
+		case 1:
+			list2 = append(list2, Item{ItemKeyID: item.ID(), "label": "changed"})
+		case 2:
+			list2 = append(list2, item, Item{ItemKeyID: fmt.Sprint("new", i)})
+		default:
+			list2 = append(list2, item)
+		}
+	}
+	return list1, list2
+}
+
+func benchmarkCompareItemList(b *testing.B, size int) {
+	list1, list2 := benchmarkItemLists(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CompareItemList(list1, list2)
+	}
+}
+
+func BenchmarkCompareItemList_1000(b *testing.B) {
+	benchmarkCompareItemList(b, 1000)
+}
+
+func BenchmarkCompareItemList_10000(b *testing.B) {
+	benchmarkCompareItemList(b, 10000)
+}
+
+func BenchmarkCompareItemList_50000(b *testing.B) {
+	benchmarkCompareItemList(b, 50000)
+}
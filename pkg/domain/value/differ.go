@@ -0,0 +1,47 @@
+package value
+
+type (
+	// Differ computes the updates between ItemLists with the job specific settings.
+	// nil Differ is same as CompareItemList.
+	Differ struct {
+		// Engine is the algorithm to compare the values.
+		// DiffEngineJSON compares the JSON values with CompareJSON and the other settings are not applied.
+		Engine DiffEngine
+		// Rules filters the changes of the numeric fields.
+		Rules ChangeRules
+		// Ignore excludes the volatile content from the comparison.
+		Ignore *IgnoreRule
+		// Move is how to report the moved items.  nil reports no moves.
+		Move *MoveOption
+		// Matcher pairs the similar removed and added items without IDs as changes.  nil disables pairing.
+		Matcher *SimilarityMatcher
+	}
+)
+
+// Structural returns true if the values should be compared as JSON instead of ItemList.
+func (d *Differ) Structural() bool {
+	return d != nil && d.Engine == DiffEngineJSON
+}
+
+// CompareJSON computes the updates between JSON values structurally.
+func (d *Differ) CompareJSON(v1, v2 interface{}) Updates {
+	return DiffJSON(v1, v2).Updates()
+}
+
+// Compare computes the updates between ItemLists.
+func (d *Differ) Compare(list1, list2 ItemList) Updates {
+	updates, _ := d.CompareSuppressed(list1, list2)
+	return updates
+}
+
+// CompareSuppressed computes the updates between ItemLists and the changes suppressed by the rules.
+// The suppressed changes should be reverted with RevertKeys before storing the list
+// so that the next check compares with the value last reported.
+func (d *Differ) CompareSuppressed(list1, list2 ItemList) (updates, suppressed Updates) {
+	if d == nil {
+		return CompareItemList(list1, list2), nil
+	}
+	updates = compareItemList(list1, list2, d.Ignore, d.Move)
+	updates = d.Matcher.Pair(updates, d.Ignore)
+	return d.Rules.Split(updates)
+}
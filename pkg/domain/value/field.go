@@ -0,0 +1,160 @@
+package value
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FieldTypeString FieldType = "string"
+	FieldTypeNumber FieldType = "number"
+	FieldTypeBool   FieldType = "bool"
+	FieldTypeTime   FieldType = "time"
+	FieldTypeList   FieldType = "list"
+	FieldTypeOther  FieldType = "other"
+)
+
+type (
+	// FieldType is the type of the Item value.
+	FieldType string
+)
+
+// TypeOf returns the type of the Item value.
+func TypeOf(v interface{}) FieldType {
+	switch v.(type) {
+	case nil, string:
+		return FieldTypeString
+	case float64, float32, int, int64, int32, uint, uint64, uint32, json.Number:
+		return FieldTypeNumber
+	case bool:
+		return FieldTypeBool
+	case time.Time:
+		return FieldTypeTime
+	case []interface{}, []string:
+		return FieldTypeList
+	default:
+		return FieldTypeOther
+	}
+}
+
+// FormatItemValue formats the Item value as the canonical string.
+// The canonical strings are used for the comparison and the IDs so that the typed values are
+// compatible with the values stored as strings by the older versions.
+func FormatItemValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case time.Time:
+		// same as the JSON representation
+		return t.Format(time.RFC3339Nano)
+	case json.Number:
+		return t.String()
+	}
+	if f, ok := ToNumber(v); ok && TypeOf(v) == FieldTypeNumber {
+		return formatNumber(f)
+	}
+	return fmt.Sprint(v)
+}
+
+// formatNumber formats the integers without the exponent and the others in the same way as fmt.Sprint.
+func formatNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(f)
+}
+
+// compareString returns the canonical string of the Item value for the comparison and the IDs.
+// The older versions stored the numbers formatted with fmt.Sprint, which uses the exponent for
+// the integers from 1e6 ("1.5e+06"), so they are normalized to the current format ("1500000").
+func compareString(v interface{}) string {
+	s := FormatItemValue(v)
+	if _, ok := v.(string); ok && strings.Contains(s, "e+") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && fmt.Sprint(f) == s {
+			return formatNumber(f)
+		}
+	}
+	return s
+}
+
+// ToNumber converts the Item value to float64.
+// Strings are parsed with ParseNumber.
+func ToNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case float32:
+		return float64(t), true
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case uint:
+		return float64(t), true
+	case uint64:
+		return float64(t), true
+	case uint32:
+		return float64(t), true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	case string:
+		f, err := ParseNumber(t)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// GetString returns the value of the key as the canonical string.
+func (i Item) GetString(key string) string {
+	return FormatItemValue(i[key])
+}
+
+// Strings returns the Item whose values are the canonical strings.
+func (i Item) Strings() map[string]string {
+	m := make(map[string]string, len(i))
+	for k, v := range i {
+		m[k] = FormatItemValue(v)
+	}
+	return m
+}
+
+// Strings returns the Items whose values are the canonical strings.
+func (i ItemList) Strings() []map[string]string {
+	l := make([]map[string]string, len(i))
+	for j, item := range i {
+		l[j] = item.Strings()
+	}
+	return l
+}
+
+// canonical returns the Item whose values are formatted with compareString.
+func (i Item) canonical() Item {
+	c := make(Item, len(i))
+	for k, v := range i {
+		c[k] = compareString(v)
+	}
+	return c
+}
+
+// itemValue converts the JSON value to the Item value.
+// The scalar values and the lists keep their types and the others are formatted as strings.
+// null is formatted in the same way as the older versions.
+func itemValue(v interface{}) interface{} {
+	if v == nil {
+		return fmt.Sprint(v)
+	}
+	if TypeOf(v) == FieldTypeOther {
+		return FormatItemValue(v)
+	}
+	return v
+}
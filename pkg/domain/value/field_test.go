@@ -0,0 +1,88 @@
+package value
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatItemValue(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"string", "abc", "abc"},
+		{"nil", nil, ""},
+		{"integer", 12800, "12800"},
+		{"integral float", 12800000.0, "12800000"},
+		{"float", 3.5, "3.5"},
+		{"bool", true, "true"},
+		{"time", time.Date(2024, 3, 1, 0, 0, 0, 0, time.FixedZone("JST", 9*60*60)), "2024-03-01T00:00:00+09:00"},
+		{"list", []interface{}{1.0, "a"}, "[1 a]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatItemValue(tt.v); got != tt.want {
+				t.Errorf("FormatItemValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemList_TypedJSON(t *testing.T) {
+	released := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	list := ItemList{Item{"price": int64(100), "sale": true, "released": released, "tags": []interface{}{"a"}}}
+	restored, err := NewItemListFromJSON(list.JSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored[0]["price"] != 100.0 || restored[0]["sale"] != true {
+		t.Errorf("typed values should be restored: %v", restored)
+	}
+	if updates := CompareItemList(list, restored); len(updates) != 0 {
+		t.Errorf("restored list should have no updates: %v", toJSON(updates))
+	}
+	// the items stored as strings by the older versions are same as the typed ones
+	legacy, err := NewItemListFromJSON(`[{"price":"100","sale":"true","released":"2024-03-01T00:00:00Z","tags":"[a]","note":null}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list[0]["note"] = ""
+	if updates := CompareItemList(legacy, list); len(updates) != 0 {
+		t.Errorf("legacy list should have no updates: %v", toJSON(updates))
+	}
+	if legacy[0].ID() != list[0].ID() {
+		t.Errorf("ID() = %s, want %s", legacy[0].ID(), list[0].ID())
+	}
+}
+
+func TestItemList_LegacyNumbers(t *testing.T) {
+	// the older versions formatted the numbers with fmt.Sprint
+	stored := `[{"id":"1.2345678e+07","price":"1.5e+06"},{"name":"a","price":"2.5e+07"},{"id":"x","code":"1e+06x","rate":"1.5e-05"}]`
+	legacy, err := NewItemListFromJSON(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := JSONArray{
+		map[string]interface{}{"id": 12345678.0, "price": 1500000.0},
+		map[string]interface{}{"name": "a", "price": 25000000.0},
+		map[string]interface{}{"id": "x", "code": "1e+06x", "rate": 0.000015},
+	}.ItemList()
+	for i := range legacy {
+		if legacy[i].ID() != current[i].ID() {
+			t.Errorf("ID() = %s, want %s", legacy[i].ID(), current[i].ID())
+		}
+	}
+	if updates := CompareItemList(legacy, current); len(updates) != 0 {
+		t.Errorf("legacy list should have no updates: %v", toJSON(updates))
+	}
+
+	current[0]["price"] = 1600000.0
+	updates := CompareItemList(legacy, current)
+	if len(updates) != 1 || updates[0].Change == nil {
+		t.Fatalf("unexpected updates: %v", toJSON(updates))
+	}
+	if c := updates[0].Change.ChangedKeys["price"]; c.Old != "1500000" || c.New != "1600000" {
+		t.Errorf("changed value = %v", c)
+	}
+}
@@ -0,0 +1,81 @@
+package value
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+type (
+	// IgnoreRule excludes the volatile content from the comparison.
+	// The rule is applied only for the comparison, not for the stored or displayed items.
+	IgnoreRule struct {
+		// Keys are the item keys not to be compared.
+		Keys []string
+		// KeyPatterns are the glob patterns of the item keys not to be compared.
+		KeyPatterns []string
+		// Masks replace the volatile part of the values before comparison.
+		Masks []ValueMask
+	}
+	// ValueMask replaces the substrings matched to Pattern with Replacement.
+	ValueMask struct {
+		Pattern     *regexp.Regexp
+		Replacement string
+	}
+)
+
+func NewIgnoreRule(keys []string, keyPatterns []string, masks []ValueMask) (*IgnoreRule, error) {
+	for _, p := range keyPatterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern: pattern=%s, err=%w", p, err)
+		}
+	}
+	return &IgnoreRule{keys, keyPatterns, masks}, nil
+}
+
+func (r *IgnoreRule) ignoreKey(key string) bool {
+	for _, k := range r.Keys {
+		if k == key {
+			return true
+		}
+	}
+	for _, p := range r.KeyPatterns {
+		if matched, _ := path.Match(p, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns the ItemList for comparison.
+// The values are formatted as the canonical strings.
+func (r *IgnoreRule) apply(list ItemList) ItemList {
+	if r == nil {
+		return list.canonical()
+	}
+	applied := make(ItemList, len(list))
+	for i, item := range list {
+		applied[i] = r.applyItem(item)
+	}
+	return applied
+}
+
+// applyItem returns the Item for comparison.
+// The values are formatted as the canonical strings.
+func (r *IgnoreRule) applyItem(item Item) Item {
+	if r == nil {
+		return item.canonical()
+	}
+	applied := make(Item, len(item))
+	for k, value := range item {
+		if r.ignoreKey(k) {
+			continue
+		}
+		v := compareString(value)
+		for _, m := range r.Masks {
+			v = m.Pattern.ReplaceAllString(v, m.Replacement)
+		}
+		applied[k] = v
+	}
+	return applied
+}
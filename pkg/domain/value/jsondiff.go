@@ -0,0 +1,303 @@
+package value
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	// DiffEngineItem compares the values as ItemList.
+	DiffEngineItem DiffEngine = "item"
+	// DiffEngineJSON compares the JSON values structurally.
+	DiffEngineJSON DiffEngine = "json"
+
+	PatchOpAdd     PatchOp = "add"
+	PatchOpRemove  PatchOp = "remove"
+	PatchOpReplace PatchOp = "replace"
+)
+
+type (
+	// DiffEngine is the algorithm to compare the values.
+	DiffEngine string
+	PatchOp    string
+	// PatchOperation is a change of the JSON value in the style of RFC 6902 JSON Patch.
+	PatchOperation struct {
+		Op PatchOp `json:"op"`
+		// Path is the JSON pointer(RFC 6901) of the changed location.
+		Path  string      `json:"path"`
+		Value interface{} `json:"value,omitempty"`
+		// Old is the value before the change.  This is not a part of RFC 6902.
+		Old interface{} `json:"old,omitempty"`
+	}
+	// JSONPatch is the list of operations to convert the old value to the new value.
+	// The operations are applicable in order.
+	JSONPatch []PatchOperation
+)
+
+func ParseDiffEngine(s string) (DiffEngine, error) {
+	switch e := DiffEngine(s); e {
+	case "":
+		return DiffEngineItem, nil
+	case DiffEngineItem, DiffEngineJSON:
+		return e, nil
+	default:
+		return "", fmt.Errorf("unsupported diff engine: %s", s)
+	}
+}
+
+// DiffJSON compares the JSON values structurally.
+// Objects are compared key by key and arrays are compared by the longest common subsequence of the elements.
+func DiffJSON(old, new interface{}) JSONPatch {
+	patch := make(JSONPatch, 0)
+	diffJSON(&patch, "", normalizeJSON(old), normalizeJSON(new))
+	return patch
+}
+
+// normalizeJSON converts the value into the types of encoding/json. (map[string]interface{}, []interface{}, float64, ...)
+func normalizeJSON(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+func diffJSON(patch *JSONPatch, path string, old, new interface{}) {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		if n, ok := new.(map[string]interface{}); ok {
+			diffJSONObject(patch, path, o, n)
+			return
+		}
+	case []interface{}:
+		if n, ok := new.([]interface{}); ok {
+			diffJSONArray(patch, path, o, n)
+			return
+		}
+	}
+	if !reflect.DeepEqual(old, new) {
+		*patch = append(*patch, PatchOperation{Op: PatchOpReplace, Path: path, Value: new, Old: old})
+	}
+}
+
+func diffJSONObject(patch *JSONPatch, path string, old, new map[string]interface{}) {
+	keys := make([]string, 0, len(old)+len(new))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range new {
+		if _, exist := old[k]; !exist {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := path + "/" + escapeJSONPointer(k)
+		o, oldExist := old[k]
+		n, newExist := new[k]
+		switch {
+		case !newExist:
+			*patch = append(*patch, PatchOperation{Op: PatchOpRemove, Path: p, Old: o})
+		case !oldExist:
+			*patch = append(*patch, PatchOperation{Op: PatchOpAdd, Path: p, Value: n})
+		default:
+			diffJSON(patch, p, o, n)
+		}
+	}
+}
+
+func diffJSONArray(patch *JSONPatch, path string, old, new []interface{}) {
+	// compare the elements as the canonical JSON
+	canonical := func(a []interface{}) []string {
+		s := make([]string, len(a))
+		for i, v := range a {
+			b, _ := json.Marshal(v)
+			s[i] = string(b)
+		}
+		return s
+	}
+	// index is the position in the array the operations are applied so far.
+	index, oldIndex, newIndex := 0, 0, 0
+	deleted := make([]interface{}, 0)
+	inserted := make([]interface{}, 0)
+	flush := func() {
+		// the pairs of the deleted and inserted elements are the changes of the elements
+		paired := len(deleted)
+		if len(inserted) < paired {
+			paired = len(inserted)
+		}
+		for i := 0; i < paired; i++ {
+			diffJSON(patch, path+"/"+strconv.Itoa(index), deleted[i], inserted[i])
+			index++
+		}
+		for _, d := range deleted[paired:] {
+			*patch = append(*patch, PatchOperation{Op: PatchOpRemove, Path: path + "/" + strconv.Itoa(index), Old: d})
+		}
+		for _, v := range inserted[paired:] {
+			*patch = append(*patch, PatchOperation{Op: PatchOpAdd, Path: path + "/" + strconv.Itoa(index), Value: v})
+			index++
+		}
+		deleted = deleted[:0]
+		inserted = inserted[:0]
+	}
+	for _, d := range diff(canonical(old), canonical(new)) {
+		switch d.diffType {
+		case diffmatchpatch.DiffDelete:
+			deleted = append(deleted, old[oldIndex])
+			oldIndex++
+		case diffmatchpatch.DiffInsert:
+			inserted = append(inserted, new[newIndex])
+			newIndex++
+		case diffmatchpatch.DiffEqual:
+			flush()
+			index++
+			oldIndex++
+			newIndex++
+		}
+	}
+	flush()
+}
+
+// escapeJSONPointer escapes the reference token of JSON pointer.
+func escapeJSONPointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// unescapeJSONPointer splits the JSON pointer into the unescaped reference tokens.
+func unescapeJSONPointer(path string) []string {
+	if path == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens
+}
+
+// Revert returns the new value of the patch where the operations of the given updates are undone.
+// The other operations are kept applied so that the updates of the different paths are handled separately.
+func (p JSONPatch) Revert(new interface{}, updates Updates) interface{} {
+	reverted := make(map[string]bool, len(updates))
+	for _, u := range updates {
+		reverted[patchKey(u.Type, u.ItemID())] = true
+	}
+	v := normalizeJSON(new)
+	// undo from the last operation not to shift the array indices of the earlier operations
+	for i := len(p) - 1; i >= 0; i-- {
+		op := p[i]
+		if reverted[patchKey(op.updateType(), op.Path)] {
+			v = op.revert(v, unescapeJSONPointer(op.Path))
+		}
+	}
+	return v
+}
+
+func patchKey(t UpdateType, path string) string {
+	return string(t) + ":" + path
+}
+
+func (op PatchOperation) updateType() UpdateType {
+	switch op.Op {
+	case PatchOpAdd:
+		return UpdateTypeAdd
+	case PatchOpRemove:
+		return UpdateTypeRemove
+	default:
+		return UpdateTypeChange
+	}
+}
+
+// revert undoes the operation at the location of the tokens in the value.
+func (op PatchOperation) revert(v interface{}, tokens []string) interface{} {
+	if len(tokens) == 0 {
+		return op.Old
+	}
+	switch c := v.(type) {
+	case map[string]interface{}:
+		key := tokens[0]
+		if len(tokens) > 1 {
+			c[key] = op.revert(c[key], tokens[1:])
+		} else if op.Op == PatchOpAdd {
+			delete(c, key)
+		} else {
+			c[key] = op.Old
+		}
+		return c
+	case []interface{}:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 || i > len(c) {
+			return c
+		}
+		if len(tokens) > 1 || op.Op == PatchOpReplace {
+			if i < len(c) {
+				c[i] = op.revert(c[i], tokens[1:])
+			}
+			return c
+		}
+		if op.Op == PatchOpAdd {
+			if i < len(c) {
+				return append(c[:i:i], c[i+1:]...)
+			}
+			return c
+		}
+		return append(c[:i:i], append([]interface{}{op.Old}, c[i:]...)...)
+	}
+	return v
+}
+
+// Updates converts the patch to the updates.
+// The item of the update has 'path' and 'value' keys and the JSON values are formatted as JSON.
+func (p JSONPatch) Updates() Updates {
+	updates := make(Updates, 0, len(p))
+	for _, op := range p {
+		switch op.Op {
+		case PatchOpAdd:
+			updates = append(updates, *updateAdd(patchItem(op.Path, op.Value)))
+		case PatchOpRemove:
+			updates = append(updates, *updateRemove(patchItem(op.Path, op.Old)))
+		case PatchOpReplace:
+			item := patchItem(op.Path, op.Value)
+			updates = append(updates, *updateChange(&ItemChange{
+				Item:        item,
+				AddedKeys:   map[string]string{},
+				RemovedKeys: map[string]string{},
+				ChangedKeys: map[string]ItemValueChange{
+					"value": {Old: formatJSONValue(op.Old), New: item.GetString("value")},
+				},
+			}))
+		}
+	}
+	return updates
+}
+
+func patchItem(path string, v interface{}) Item {
+	return Item{
+		ItemKeyID: path,
+		"path":    path,
+		"label":   path,
+		"value":   formatJSONValue(v),
+	}
+}
+
+// formatJSONValue formats the string as is and the others as JSON.
+func formatJSONValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,132 @@
+package value
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new interface{}
+		want     JSONPatch
+	}{
+		{
+			name: "same",
+			old:  map[string]interface{}{"a": 1, "b": []interface{}{"x"}},
+			new:  map[string]interface{}{"a": 1, "b": []interface{}{"x"}},
+			want: JSONPatch{},
+		},
+		{
+			name: "nested object",
+			old:  map[string]interface{}{"a": map[string]interface{}{"b": 1, "c/d": true}},
+			new:  map[string]interface{}{"a": map[string]interface{}{"b": 2, "e": "x"}},
+			want: JSONPatch{
+				{Op: PatchOpReplace, Path: "/a/b", Value: 2.0, Old: 1.0},
+				{Op: PatchOpRemove, Path: "/a/c~1d", Old: true},
+				{Op: PatchOpAdd, Path: "/a/e", Value: "x"},
+			},
+		},
+		{
+			name: "array",
+			old:  []interface{}{"a", "b", "c", "d"},
+			new:  []interface{}{"a", "c", "x", "d", "e"},
+			want: JSONPatch{
+				{Op: PatchOpRemove, Path: "/1", Old: "b"},
+				{Op: PatchOpAdd, Path: "/2", Value: "x"},
+				{Op: PatchOpAdd, Path: "/4", Value: "e"},
+			},
+		},
+		{
+			name: "changed array element",
+			old:  []interface{}{map[string]interface{}{"id": 1, "price": 100}, "b"},
+			new:  []interface{}{map[string]interface{}{"id": 1, "price": 200}, "b"},
+			want: JSONPatch{
+				{Op: PatchOpReplace, Path: "/0/price", Value: 200.0, Old: 100.0},
+			},
+		},
+		{
+			name: "type changed",
+			old:  map[string]interface{}{"a": []interface{}{1}},
+			new:  map[string]interface{}{"a": "1"},
+			want: JSONPatch{
+				{Op: PatchOpReplace, Path: "/a", Value: "1", Old: []interface{}{1.0}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffJSON(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffJSON() = %v, want %v", toJSON(got), toJSON(tt.want))
+			}
+		})
+	}
+}
+
+func TestJSONPatch_Updates(t *testing.T) {
+	patch := JSONPatch{
+		{Op: PatchOpAdd, Path: "/a", Value: map[string]interface{}{"b": 1}},
+		{Op: PatchOpReplace, Path: "/c", Value: "new", Old: "old"},
+	}
+	got := patch.Updates()
+	if len(got) != 2 || got[0].Type != UpdateTypeAdd || got[1].Type != UpdateTypeChange {
+		t.Fatalf("JSONPatch.Updates() = %v", toJSON(got))
+	}
+	if v := got[0].Add["value"]; v != `{"b":1}` {
+		t.Errorf("added value = %s", v)
+	}
+	if c := got[1].Change.ChangedKeys["value"]; c.Old != "old" || c.New != "new" {
+		t.Errorf("changed value = %v", c)
+	}
+}
+
+func TestJSONPatch_Revert(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new interface{}
+		revert   []int
+		want     interface{}
+	}{
+		{
+			name:   "object",
+			old:    map[string]interface{}{"a": 1, "b": 1, "c": true},
+			new:    map[string]interface{}{"a": 2, "b": 2, "d": "x"},
+			revert: []int{0, 2, 3},
+			want:   map[string]interface{}{"a": 1.0, "b": 2.0, "c": true},
+		},
+		{
+			name:   "array",
+			old:    []interface{}{"a", "b", "c", "d"},
+			new:    []interface{}{"a", "c", "x", "d", "e"},
+			revert: []int{0, 2},
+			want:   []interface{}{"a", "b", "c", "x", "d"},
+		},
+		{
+			name:   "nested",
+			old:    map[string]interface{}{"items": []interface{}{map[string]interface{}{"price": 100}, map[string]interface{}{"price": 200}}},
+			new:    map[string]interface{}{"items": []interface{}{map[string]interface{}{"price": 110}, map[string]interface{}{"price": 210}}},
+			revert: []int{1},
+			want:   map[string]interface{}{"items": []interface{}{map[string]interface{}{"price": 110.0}, map[string]interface{}{"price": 200.0}}},
+		},
+		{
+			name:   "all",
+			old:    []interface{}{"a", "b", "c", "d"},
+			new:    []interface{}{"a", "c", "x", "d", "e"},
+			revert: []int{0, 1, 2},
+			want:   []interface{}{"a", "b", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := DiffJSON(tt.old, tt.new)
+			all := patch.Updates()
+			updates := make(Updates, 0, len(tt.revert))
+			for _, i := range tt.revert {
+				updates = append(updates, all[i])
+			}
+			if got := patch.Revert(tt.new, updates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JSONPatch.Revert() = %v, want %v", toJSON(got), toJSON(tt.want))
+			}
+		})
+	}
+}
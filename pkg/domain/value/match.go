@@ -0,0 +1,160 @@
+package value
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	// DefaultSimilarityThreshold is the default threshold of SimilarityMatcher.
+	DefaultSimilarityThreshold = 0.8
+)
+
+type (
+	// SimilarityMatcher pairs the removed item and the added item which don't have explicit IDs.
+	// The paired items are reported as a change instead of remove and add.
+	SimilarityMatcher struct {
+		// Fields are the keys to compute the similarity.
+		// If empty, all keys are used.
+		Fields []string
+		// Threshold is the minimum similarity(0 to 1) to pair items.
+		Threshold float64
+	}
+	matchCandidate struct {
+		removeIndex int
+		addIndex    int
+		similarity  float64
+	}
+)
+
+// Pair replaces the pairs of the similar removed item and added item with changes.
+// The change is placed at the position of the added item.
+func (m *SimilarityMatcher) Pair(updates Updates, ignore *IgnoreRule) Updates {
+	if m == nil {
+		return updates
+	}
+	threshold := m.Threshold
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+	candidates := make([]matchCandidate, 0)
+	for i, removed := range updates {
+		if removed.Type != UpdateTypeRemove || removed.Remove.hasID() {
+			continue
+		}
+		for j, added := range updates {
+			if added.Type != UpdateTypeAdd || added.Add.hasID() {
+				continue
+			}
+			s := m.Similarity(ignore.applyItem(removed.Remove), ignore.applyItem(added.Add))
+			if s >= threshold {
+				candidates = append(candidates, matchCandidate{i, j, s})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return updates
+	}
+	// pair the most similar items first
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+	paired := make(map[int]bool)
+	changes := make(map[int]*ItemChange)
+	for _, c := range candidates {
+		if paired[c.removeIndex] || paired[c.addIndex] {
+			continue
+		}
+		paired[c.removeIndex] = true
+		paired[c.addIndex] = true
+		item1 := updates[c.removeIndex].Remove
+		item2 := updates[c.addIndex].Add
+		if changed := compareItem(ignore.applyItem(item1), ignore.applyItem(item2)); changed != nil {
+			changes[c.addIndex] = changed.restore(item1, item2)
+		}
+	}
+	result := make(Updates, 0, len(updates))
+	for i, u := range updates {
+		if change, exist := changes[i]; exist {
+			result = append(result, *updateChange(change))
+			continue
+		}
+		if paired[i] {
+			continue
+		}
+		result = append(result, u)
+	}
+	return result
+}
+
+// Similarity computes the similarity of the items from 0 to 1.
+// The similarity is the average of the string similarities of the values.
+// Missing keys are counted as 0 and keys which are empty in both items are skipped.
+func (m *SimilarityMatcher) Similarity(item1, item2 Item) float64 {
+	keys := m.Fields
+	if len(keys) == 0 {
+		keys = make([]string, 0)
+		for k := range item1 {
+			keys = append(keys, k)
+		}
+		for k := range item2 {
+			if _, exist := item1[k]; !exist {
+				keys = append(keys, k)
+			}
+		}
+	}
+	var total float64
+	count := 0
+	for _, k := range keys {
+		_, exist1 := item1[k]
+		_, exist2 := item2[k]
+		v1 := item1.GetString(k)
+		v2 := item2.GetString(k)
+		if v1 == "" && v2 == "" {
+			continue
+		}
+		count++
+		if exist1 && exist2 {
+			total += stringSimilarity(v1, v2)
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// stringSimilarity is 1 - (levenshtein distance / length of the longer string).
+func stringSimilarity(s1, s2 string) float64 {
+	if s1 == s2 {
+		return 1
+	}
+	length := utf8.RuneCountInString(s1)
+	if l := utf8.RuneCountInString(s2); l > length {
+		length = l
+	}
+	dmp := diffmatchpatch.New()
+	distance, insertions, deletions := 0, 0, 0
+	for _, d := range dmp.DiffMain(s1, s2, false) {
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			insertions += utf8.RuneCountInString(d.Text)
+		case diffmatchpatch.DiffDelete:
+			deletions += utf8.RuneCountInString(d.Text)
+		case diffmatchpatch.DiffEqual:
+			distance += maxInt(insertions, deletions)
+			insertions, deletions = 0, 0
+		}
+	}
+	distance += maxInt(insertions, deletions)
+	return 1 - float64(distance)/float64(length)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
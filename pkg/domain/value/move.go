@@ -0,0 +1,42 @@
+package value
+
+import "fmt"
+
+const (
+	MoveModeReport MoveMode = "report"
+	MoveModeIgnore MoveMode = "ignore"
+)
+
+type (
+	// MoveMode is how to handle the moved items.
+	MoveMode   string
+	MoveOption struct {
+		Mode MoveMode
+		// MinDistance reports only the moves beyond this number of positions.
+		MinDistance int
+	}
+)
+
+func ParseMoveMode(s string) (MoveMode, error) {
+	switch m := MoveMode(s); m {
+	case "":
+		return MoveModeReport, nil
+	case MoveModeReport, MoveModeIgnore:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported move mode: %s", s)
+	}
+}
+
+// report returns true if the move should be reported.
+// nil MoveOption reports no moves not to notify the reorders to the existing jobs.
+func (m *MoveOption) report(from, to int) bool {
+	if m == nil || m.Mode == MoveModeIgnore {
+		return false
+	}
+	distance := to - from
+	if distance < 0 {
+		distance = -distance
+	}
+	return distance > m.MinDistance
+}
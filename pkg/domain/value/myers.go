@@ -0,0 +1,196 @@
+package value
+
+import "github.com/sergi/go-diff/diffmatchpatch"
+
+type (
+	// myers computes the shortest edit script between the slices with the linear space variant of Myers' algorithm.
+	// The elements are compared as the integer symbols.
+	myers struct {
+		a, b  []int
+		diffs []diffItem
+		// texts are the elements of the original slices indexed by the symbols.
+		texts []string
+	}
+)
+
+// diff computes the differences between the slices.
+// Each diffItem has an element and the deletions come before the insertions in each hunk.
+func diff(a, b []string) []diffItem {
+	symbols := make(map[string]int, len(a)+len(b))
+	texts := make([]string, 0, len(a)+len(b))
+	encode := func(s []string) []int {
+		encoded := make([]int, len(s))
+		for i, v := range s {
+			sym, exist := symbols[v]
+			if !exist {
+				sym = len(texts)
+				symbols[v] = sym
+				texts = append(texts, v)
+			}
+			encoded[i] = sym
+		}
+		return encoded
+	}
+	m := &myers{
+		a:     encode(a),
+		b:     encode(b),
+		diffs: make([]diffItem, 0, len(a)+len(b)),
+		texts: texts,
+	}
+	m.compare(0, len(m.a), 0, len(m.b))
+	return m.merge()
+}
+
+// compare appends the diffs between a[aStart:aEnd] and b[bStart:bEnd].
+func (m *myers) compare(aStart, aEnd, bStart, bEnd int) {
+	// common prefix
+	for aStart < aEnd && bStart < bEnd && m.a[aStart] == m.b[bStart] {
+		m.append(diffmatchpatch.DiffEqual, m.a[aStart])
+		aStart++
+		bStart++
+	}
+	// common suffix is appended after the middle part
+	suffix := 0
+	for aStart < aEnd-suffix && bStart < bEnd-suffix && m.a[aEnd-suffix-1] == m.b[bEnd-suffix-1] {
+		suffix++
+	}
+	aEnd -= suffix
+	bEnd -= suffix
+
+	switch {
+	case aStart == aEnd:
+		for _, sym := range m.b[bStart:bEnd] {
+			m.append(diffmatchpatch.DiffInsert, sym)
+		}
+	case bStart == bEnd:
+		for _, sym := range m.a[aStart:aEnd] {
+			m.append(diffmatchpatch.DiffDelete, sym)
+		}
+	default:
+		x, y := m.bisect(aStart, aEnd, bStart, bEnd)
+		if x < 0 {
+			// no common elements
+			for _, sym := range m.a[aStart:aEnd] {
+				m.append(diffmatchpatch.DiffDelete, sym)
+			}
+			for _, sym := range m.b[bStart:bEnd] {
+				m.append(diffmatchpatch.DiffInsert, sym)
+			}
+		} else {
+			m.compare(aStart, x, bStart, y)
+			m.compare(x, aEnd, y, bEnd)
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		m.append(diffmatchpatch.DiffEqual, m.a[aEnd+i])
+	}
+}
+
+// bisect finds the middle snake of the edit graph and returns the split point.
+// Returns (-1, -1) if the slices have no common elements.
+func (m *myers) bisect(aStart, aEnd, bStart, bEnd int) (int, int) {
+	a := m.a[aStart:aEnd]
+	b := m.b[bStart:bEnd]
+	n, l := len(a), len(b)
+	maxD := (n + l + 1) / 2
+	vOffset := maxD
+	vLength := 2*maxD + 2
+	v1 := make([]int, vLength)
+	v2 := make([]int, vLength)
+	for i := range v1 {
+		v1[i] = -1
+		v2[i] = -1
+	}
+	v1[vOffset+1] = 0
+	v2[vOffset+1] = 0
+	delta := n - l
+	// If the total number of elements is odd, the front path collides with the reverse path.
+	front := delta%2 != 0
+	// Offsets for start and end of k loop to prevent mapping of space beyond the grid.
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		// forward path
+		for k1 := -d + k1start; k1 <= d-k1end; k1 += 2 {
+			k1Offset := vOffset + k1
+			var x1 int
+			if k1 == -d || (k1 != d && v1[k1Offset-1] < v1[k1Offset+1]) {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < l && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[k1Offset] = x1
+			if x1 > n {
+				k1end += 2
+			} else if y1 > l {
+				k1start += 2
+			} else if front {
+				k2Offset := vOffset + delta - k1
+				if k2Offset >= 0 && k2Offset < vLength && v2[k2Offset] != -1 {
+					if x1 >= n-v2[k2Offset] {
+						return aStart + x1, bStart + y1
+					}
+				}
+			}
+		}
+		// reverse path
+		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
+			k2Offset := vOffset + k2
+			var x2 int
+			if k2 == -d || (k2 != d && v2[k2Offset-1] < v2[k2Offset+1]) {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < l && a[n-x2-1] == b[l-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2Offset] = x2
+			if x2 > n {
+				k2end += 2
+			} else if y2 > l {
+				k2start += 2
+			} else if !front {
+				k1Offset := vOffset + delta - k2
+				if k1Offset >= 0 && k1Offset < vLength && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					y1 := vOffset + x1 - k1Offset
+					if x1 >= n-x2 {
+						return aStart + x1, bStart + y1
+					}
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+func (m *myers) append(op diffmatchpatch.Operation, sym int) {
+	m.diffs = append(m.diffs, diffItem{op, m.texts[sym]})
+}
+
+// merge reorders each hunk so that the deletions come before the insertions.
+func (m *myers) merge() []diffItem {
+	merged := make([]diffItem, 0, len(m.diffs))
+	inserts := make([]diffItem, 0)
+	for _, d := range m.diffs {
+		switch d.diffType {
+		case diffmatchpatch.DiffDelete:
+			merged = append(merged, d)
+		case diffmatchpatch.DiffInsert:
+			inserts = append(inserts, d)
+		default:
+			merged = append(merged, inserts...)
+			inserts = inserts[:0]
+			merged = append(merged, d)
+		}
+	}
+	return append(merged, inserts...)
+}
@@ -0,0 +1,118 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/width"
+)
+
+var (
+	numberPattern     = regexp.MustCompile(`([-−]?)(\d[\d,]*(?:\.\d+)?)\s*(兆|億|万|千)?`)
+	eraPattern        = regexp.MustCompile(`(明治|大正|昭和|平成|令和)\s*(元|\d{1,2})\s*年`)
+	eraLetterPattern  = regexp.MustCompile(`(^|[^A-Za-z])([MTSHR])(\d{1,2})([./-])`)
+	weekdayPattern    = regexp.MustCompile(`\s*[(（][月火水木金土日](曜日?)?[)）]`)
+	numberUnits       = map[string]float64{"千": 1e3, "万": 1e4, "億": 1e8, "兆": 1e12}
+	eraFirstYears     = map[string]int{"明治": 1868, "大正": 1912, "昭和": 1926, "平成": 1989, "令和": 2019}
+	eraLetters        = map[string]string{"M": "明治", "T": "大正", "S": "昭和", "H": "平成", "R": "令和"}
+	defaultDateLayout = []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"2006/1/2 15:04:05",
+		"2006/1/2 15:04",
+		"2006/1/2",
+		"2006.1.2",
+		"2006年1月2日 15時4分",
+		"2006年1月2日 15:04",
+		"2006年1月2日",
+		"2006年1月",
+	}
+)
+
+// ParseNumber extracts a number from the human readable string like "¥12,800（税込）" or "1.5万円".
+// Full-width characters, currency symbols, thousands separators and the Japanese units(千, 万, 億, 兆) are supported.
+func ParseNumber(s string) (float64, error) {
+	normalized := width.Fold.String(s)
+	matches := numberPattern.FindAllStringSubmatchIndex(normalized, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("no number found: %s", s)
+	}
+	var result float64
+	var negative bool
+	end := -1
+	for i, m := range matches {
+		// "1億2000万" consists of multiple adjacent numbers with units.
+		if i > 0 && (matches[i-1][6] < 0 || m[0] != end) {
+			break
+		}
+		if i == 0 {
+			negative = m[3] > m[2]
+		}
+		n, err := strconv.ParseFloat(strings.ReplaceAll(normalized[m[4]:m[5]], ",", ""), 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse number: value=%s, err=%w", s, err)
+		}
+		if m[6] >= 0 {
+			n *= numberUnits[normalized[m[6]:m[7]]]
+		}
+		result += n
+		end = m[1]
+	}
+	if negative {
+		result = -result
+	}
+	return result, nil
+}
+
+// NumberInterface converts the float to the value suitable for JSON.
+// Integral values are converted to int64 so that they are not formatted with the exponent.
+func NumberInterface(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
+
+// ParseTime parses the human readable date string with the layouts.
+// Japanese era(e.g. 令和6年, R6.) and weekdays in parentheses(e.g. (金)) are normalized before parsing.
+// If layouts are empty, the common layouts are used.
+func ParseTime(s string, layouts []string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	if len(layouts) == 0 {
+		layouts = defaultDateLayout
+	}
+	normalized := normalizeDate(s)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, normalized, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no layout matched: value=%s, layouts=%v", s, layouts)
+}
+
+func normalizeDate(s string) string {
+	s = strings.TrimSpace(width.Fold.String(s))
+	s = weekdayPattern.ReplaceAllString(s, "")
+	s = eraLetterPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := eraLetterPattern.FindStringSubmatch(m)
+		year, _ := strconv.Atoi(sub[3])
+		return fmt.Sprintf("%s%d%s", sub[1], eraFirstYears[eraLetters[sub[2]]]+year-1, sub[4])
+	})
+	s = eraPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := eraPattern.FindStringSubmatch(m)
+		year := 1
+		if sub[2] != "元" {
+			year, _ = strconv.Atoi(sub[2])
+		}
+		return fmt.Sprintf("%d年", eraFirstYears[sub[1]]+year-1)
+	})
+	return s
+}
@@ -0,0 +1,72 @@
+package value
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    float64
+		wantErr bool
+	}{
+		{s: "12800", want: 12800},
+		{s: "¥12,800（税込）", want: 12800},
+		{s: "￥１２，８００", want: 12800},
+		{s: "$1,234.56", want: 1234.56},
+		{s: "1.5万円", want: 15000},
+		{s: "1億2000万円", want: 120000000},
+		{s: "約3万件 (前年比 2倍)", want: 30000},
+		{s: "-300円", want: -300},
+		{s: "57%OFF", want: 57},
+		{s: "価格未定", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseNumber(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseNumber() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		s       string
+		layouts []string
+		want    string
+		wantErr bool
+	}{
+		{s: "2024-03-01", want: "2024-03-01T00:00:00Z"},
+		{s: "2024/3/1 10:30", want: "2024-03-01T10:30:00Z"},
+		{s: "2024年3月1日", want: "2024-03-01T00:00:00Z"},
+		{s: "２０２４年３月１日(金)", want: "2024-03-01T00:00:00Z"},
+		{s: "令和6年3月1日", want: "2024-03-01T00:00:00Z"},
+		{s: "平成元年1月8日", want: "1989-01-08T00:00:00Z"},
+		{s: "R6.3.1", want: "2024-03-01T00:00:00Z"},
+		{s: "03/01/2024", layouts: []string{"01/02/2006"}, want: "2024-03-01T00:00:00Z"},
+		{s: "2024年3月1日", layouts: []string{"01/02/2006"}, wantErr: true},
+		{s: "not a date", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseTime(tt.s, tt.layouts, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if s := got.Format(time.RFC3339); s != tt.want {
+				t.Errorf("ParseTime() = %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
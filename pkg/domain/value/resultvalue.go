@@ -21,20 +21,22 @@ const (
 	UpdateTypeAdd          UpdateType = "add"
 	UpdateTypeRemove       UpdateType = "remove"
 	UpdateTypeChange       UpdateType = "change"
+	UpdateTypeMove         UpdateType = "move"
 )
 
 type (
 	// Item is the free format key-value object
 	// In case you watch the search result,
 	// each search result item should be represented as Item.
-	Item map[string]string
+	// The values are either of string, number, bool, time.Time or list. (See TypeOf)
+	Item map[string]interface{}
 
 	// ItemList is the ordered list of the Item.
 	ItemList []Item
 
 	// Updates is the result of Diff function.
 	Updates []Update
-	// UpdateType represents one of the add, remove, change.
+	// UpdateType represents one of the add, remove, change, move.
 	UpdateType string
 	// Update is the item update
 	Update struct {
@@ -44,6 +46,8 @@ type (
 		// Remove is an event represents Item removed
 		Remove Item
 		Change *ItemChange
+		// Move is an event represents Item moved to the different position
+		Move *ItemMove
 	}
 	// ItemChange represents changed item.
 	ItemChange struct {
@@ -54,6 +58,16 @@ type (
 		RemovedKeys map[string]string `json:"remove,omitempty"`
 		// ChangedKeys exist in both old and new one but their value was changed.
 		ChangedKeys map[string]ItemValueChange `json:"change,omitempty"`
+		// previousID is the ID of the item before the change to find it in the previous ItemList.
+		previousID string
+	}
+	// ItemMove represents the item which exists in both lists at the different positions.
+	ItemMove struct {
+		Item Item `json:"item"`
+		// From is the index in the old list.
+		From int `json:"from"`
+		// To is the index in the new list.
+		To int `json:"to"`
 	}
 	// ItemValueChange has a change of the Item value.
 	ItemValueChange struct {
@@ -74,13 +88,17 @@ func updateChange(item *ItemChange) *Update {
 	return &Update{Type: UpdateTypeChange, Change: item}
 }
 
+func updateMove(move *ItemMove) *Update {
+	return &Update{Type: UpdateTypeMove, Move: move}
+}
+
 func (u *Update) UnmarshalJSON(data []byte) error {
 	var m map[UpdateType]interface{}
 	if err := json.Unmarshal(data, &m); err != nil {
 		return fmt.Errorf("failed to unmarshal:%w", err)
 	}
 	if len(m) != 1 {
-		return errors.New("Either of 'add', 'remove', 'change' is required")
+		return errors.New("Either of 'add', 'remove', 'change', 'move' is required")
 	}
 	for k, v := range m {
 		u.Type = k
@@ -115,6 +133,16 @@ func (u *Update) UnmarshalJSON(data []byte) error {
 				return fmt.Errorf("failed to unmarshal:%w", err)
 			}
 			u.Change = &change
+		case UpdateTypeMove:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("failed to marshal:%w", err)
+			}
+			var move ItemMove
+			if err := json.Unmarshal(b, &move); err != nil {
+				return fmt.Errorf("failed to unmarshal:%w", err)
+			}
+			u.Move = &move
 		}
 		break
 	}
@@ -131,6 +159,9 @@ func (u *Update) Item() interface{} {
 	if u.Change != nil {
 		return u.Change
 	}
+	if u.Move != nil {
+		return u.Move
+	}
 	return nil
 }
 
@@ -144,6 +175,9 @@ func (u *Update) ItemID() string {
 	if u.Change != nil {
 		return u.Change.Item.ID()
 	}
+	if u.Move != nil {
+		return u.Move.Item.ID()
+	}
 	return ""
 }
 
@@ -163,6 +197,34 @@ func NewItemListFromJSON(j string) (i ItemList, err error) {
 	return
 }
 
+// UnmarshalJSON reads null as the empty string in the same way as the older versions.
+func (i *Item) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if m == nil {
+		*i = nil
+		return nil
+	}
+	for k, v := range m {
+		if v == nil {
+			m[k] = ""
+		}
+	}
+	*i = m
+	return nil
+}
+
+// canonical returns the ItemList whose values are formatted with compareString.
+func (i ItemList) canonical() ItemList {
+	c := make(ItemList, len(i))
+	for j, item := range i {
+		c[j] = item.canonical()
+	}
+	return c
+}
+
 // Clone returns the deep-copied struct.
 func (i ItemList) Clone() ItemList {
 	clone := make([]Item, len(i))
@@ -215,7 +277,7 @@ func (i ItemList) forCompare() ItemList {
 
 // Clone returns the deep-copied struct.
 func (i Item) Clone() Item {
-	clone := make(map[string]string, len(i))
+	clone := make(Item, len(i))
 	for k, v := range i {
 		clone[k] = v
 	}
@@ -238,14 +300,14 @@ func (i Item) forCompare() Item {
 }
 
 // NewItem create new Item
-func NewItem(m map[string]string) Item {
+func NewItem(m map[string]interface{}) Item {
 	item := Item(m).Clone()
 	return item
 }
 
 // NewItemFromJSON create a new Item from JSON object string.
 func NewItemFromJSON(jsonString string) (Item, error) {
-	var m map[string]string
+	var m map[string]interface{}
 	if err := json.Unmarshal([]byte(jsonString), &m); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal json string: json=%s, err=%w", jsonString, err)
 	}
@@ -264,13 +326,20 @@ func NewItemForSource(s string) Item {
 func (i Item) ID() string {
 	id, exist := i[ItemKeyID]
 	if exist {
-		return id
+		return compareString(id)
 	}
-	j := i.JSON()
+	// compute from the canonical strings not to change the IDs of the items stored as strings
+	j := i.canonical().JSON()
 	sum := md5.Sum([]byte(j))
 	return hex.EncodeToString(sum[:])
 }
 
+// hasID returns true if the item has the explicit ID.
+func (i Item) hasID() bool {
+	_, exist := i[ItemKeyID]
+	return exist
+}
+
 // SetID set id.
 func (i Item) SetID(id string) {
 	i[ItemKeyID] = id
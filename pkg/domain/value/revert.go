@@ -0,0 +1,140 @@
+package value
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+)
+
+// UpdateKey returns the key to identify the same update across the checks.
+// The ignore rule is applied so that the volatile content doesn't change the key.
+func (d *Differ) UpdateKey(u Update) string {
+	var ignore *IgnoreRule
+	if d != nil {
+		ignore = d.Ignore
+	}
+	var content string
+	switch u.Type {
+	case UpdateTypeAdd:
+		content = ignore.applyItem(u.Add).ID()
+	case UpdateTypeRemove:
+		content = ignore.applyItem(u.Remove).ID()
+	case UpdateTypeChange:
+		// the same item changed to the different value is the different update
+		content = ignore.applyItem(u.Change.Item).JSON()
+	case UpdateTypeMove:
+		content = ignore.applyItem(u.Move.Item).ID()
+	}
+	sum := md5.Sum([]byte(content))
+	return string(u.Type) + ":" + hex.EncodeToString(sum[:])
+}
+
+// RevertUpdates returns the current ItemList where the given updates are undone.
+// The changed items are replaced with the original items in the previous ItemList
+// to keep the internal keys and the value types.
+// The removed items are restored at their positions in the previous ItemList.
+// Moves are not reverted because they don't change the items.
+func RevertUpdates(previous, current ItemList, updates Updates) ItemList {
+	if len(updates) == 0 {
+		return current
+	}
+	previousByID := previous.indexByID()
+	added := make(map[string]int)
+	changed := make(map[string]Item)
+	removed := make(map[string]Item)
+	for _, u := range updates {
+		switch u.Type {
+		case UpdateTypeAdd:
+			added[u.Add.ID()]++
+		case UpdateTypeChange:
+			old, exist := previousByID[u.Change.previousID]
+			if !exist {
+				old = u.Change.previousItem()
+			}
+			changed[u.Change.Item.ID()] = old
+		case UpdateTypeRemove:
+			removed[u.Remove.ID()] = u.Remove
+		}
+	}
+	reverted := make(ItemList, 0, len(current)+len(removed))
+	for _, item := range current {
+		id := item.forCompare().ID()
+		if added[id] > 0 {
+			added[id]--
+			continue
+		}
+		if old, exist := changed[id]; exist {
+			delete(changed, id)
+			reverted = append(reverted, old)
+			continue
+		}
+		reverted = append(reverted, item)
+	}
+	for i, item := range previous {
+		if _, exist := removed[item.forCompare().ID()]; !exist {
+			continue
+		}
+		if i > len(reverted) {
+			i = len(reverted)
+		}
+		reverted = append(reverted[:i], append(ItemList{item}, reverted[i:]...)...)
+	}
+	return reverted
+}
+
+// RevertKeys returns the current ItemList where the changed keys of the given changes are undone.
+// The other keys of the changed items are kept.
+func RevertKeys(previous, current ItemList, changes Updates) ItemList {
+	if len(changes) == 0 {
+		return current
+	}
+	previousByID := previous.indexByID()
+	changed := make(map[string]*ItemChange)
+	for _, u := range changes {
+		if u.Type == UpdateTypeChange {
+			changed[u.Change.Item.ID()] = u.Change
+		}
+	}
+	reverted := make(ItemList, len(current))
+	for i, item := range current {
+		change, exist := changed[item.forCompare().ID()]
+		if !exist {
+			reverted[i] = item
+			continue
+		}
+		old, exist := previousByID[change.previousID]
+		item = item.Clone()
+		for k, v := range change.ChangedKeys {
+			if exist {
+				item[k] = old[k]
+			} else {
+				item[k] = v.Old
+			}
+		}
+		reverted[i] = item
+	}
+	return reverted
+}
+
+// indexByID returns the original items by the IDs used in the comparison.
+func (i ItemList) indexByID() map[string]Item {
+	index := make(map[string]Item, len(i))
+	for _, item := range i {
+		index[item.forCompare().ID()] = item
+	}
+	return index
+}
+
+// previousItem rebuilds the item before the change if the original item is not found.
+func (u *ItemChange) previousItem() Item {
+	item := u.Item.Clone()
+	for k := range u.AddedKeys {
+		delete(item, k)
+	}
+	for k, v := range u.RemovedKeys {
+		item[k] = v
+	}
+	for k, v := range u.ChangedKeys {
+		item[k] = v.Old
+	}
+	return item
+}
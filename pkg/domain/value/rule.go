@@ -0,0 +1,154 @@
+package value
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	DirectionAny      Direction = "any"
+	DirectionIncrease Direction = "increase"
+	DirectionDecrease Direction = "decrease"
+)
+
+type (
+	// Direction is the direction of the numeric change.
+	Direction string
+	// ChangeRule is the condition to report the change of the numeric field.
+	// All of the specified conditions should be satisfied.
+	ChangeRule struct {
+		// Key is the item key to apply this rule.
+		Key string
+		// Absolute is the minimum absolute difference.
+		Absolute *float64
+		// Percent is the minimum difference in percent of the old value.
+		Percent *float64
+		// Direction limits the direction of the change.
+		Direction Direction
+		// Below requires the new value to be below this value.
+		Below *float64
+		// Above requires the new value to be above this value.
+		Above *float64
+		// Cross requires the old value and the new value to be on the different sides of this value.
+		Cross *float64
+	}
+	// ChangeRules is the list of ChangeRule.
+	// A change of the key is reported if any of the rules for the key is satisfied.
+	ChangeRules []ChangeRule
+)
+
+func ParseDirection(s string) (Direction, error) {
+	switch d := Direction(s); d {
+	case "":
+		return DirectionAny, nil
+	case DirectionAny, DirectionIncrease, DirectionDecrease:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unsupported direction: %s", s)
+	}
+}
+
+// Match returns true if the change from old to new satisfies the rule.
+func (r *ChangeRule) Match(old, new float64) bool {
+	diff := new - old
+	switch r.Direction {
+	case DirectionIncrease:
+		if diff <= 0 {
+			return false
+		}
+	case DirectionDecrease:
+		if diff >= 0 {
+			return false
+		}
+	}
+	if r.Absolute != nil && math.Abs(diff) < *r.Absolute {
+		return false
+	}
+	if r.Percent != nil {
+		if old == 0 {
+			if diff == 0 {
+				return false
+			}
+		} else if math.Abs(diff/old)*100 < *r.Percent {
+			return false
+		}
+	}
+	if r.Below != nil && !(new < *r.Below) {
+		return false
+	}
+	if r.Above != nil && !(new > *r.Above) {
+		return false
+	}
+	if r.Cross != nil && (old < *r.Cross) == (new < *r.Cross) {
+		return false
+	}
+	return true
+}
+
+// Filter drops the changes which don't satisfy the rules.
+// Updates which have no changes after filtering are dropped.
+func (rules ChangeRules) Filter(updates Updates) Updates {
+	filtered, _ := rules.Split(updates)
+	return filtered
+}
+
+// Split splits the updates into the ones to report and the changes dropped by the rules.
+// The dropped changes have only the changed keys which don't satisfy the rules.
+func (rules ChangeRules) Split(updates Updates) (filtered, dropped Updates) {
+	if len(rules) == 0 {
+		return updates, nil
+	}
+	filtered = make(Updates, 0, len(updates))
+	for _, u := range updates {
+		if u.Type != UpdateTypeChange || u.Change == nil {
+			filtered = append(filtered, u)
+			continue
+		}
+		changedKeys := make(map[string]ItemValueChange)
+		droppedKeys := make(map[string]ItemValueChange)
+		for k, c := range u.Change.ChangedKeys {
+			if rules.match(k, c) {
+				changedKeys[k] = c
+			} else {
+				droppedKeys[k] = c
+			}
+		}
+		if len(droppedKeys) > 0 {
+			change := *u.Change
+			change.AddedKeys = map[string]string{}
+			change.RemovedKeys = map[string]string{}
+			change.ChangedKeys = droppedKeys
+			dropped = append(dropped, *updateChange(&change))
+		}
+		if len(changedKeys) == 0 && len(u.Change.AddedKeys) == 0 && len(u.Change.RemovedKeys) == 0 {
+			continue
+		}
+		change := *u.Change
+		change.ChangedKeys = changedKeys
+		filtered = append(filtered, *updateChange(&change))
+	}
+	return filtered, dropped
+}
+
+func (rules ChangeRules) match(key string, c ItemValueChange) bool {
+	hasRule := false
+	for _, r := range rules {
+		if r.Key != key {
+			continue
+		}
+		hasRule = true
+		old, err := ParseNumber(c.Old)
+		if err != nil {
+			// report the change if it can't be evaluated
+			return true
+		}
+		new, err := ParseNumber(c.New)
+		if err != nil {
+			return true
+		}
+		if r.Match(old, new) {
+			return true
+		}
+	}
+	return !hasRule
+}
@@ -0,0 +1,67 @@
+package value
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangeRules_Filter(t *testing.T) {
+	five := 5.
+	target := 3000.
+	rules := ChangeRules{
+		{Key: "price", Percent: &five},
+		{Key: "price", Below: &target, Direction: DirectionDecrease},
+	}
+	list1 := ItemList{
+		Item{ItemKeyID: "1", "price": "¥10,000", "title": "A"},
+		Item{ItemKeyID: "2", "price": "¥10,000", "title": "B"},
+		Item{ItemKeyID: "3", "price": "¥3,100", "title": "C"},
+		Item{ItemKeyID: "4", "price": "¥10,000", "title": "D"},
+	}
+	list2 := ItemList{
+		Item{ItemKeyID: "1", "price": "¥10,010", "title": "A"},
+		Item{ItemKeyID: "2", "price": "¥9,000", "title": "B"},
+		Item{ItemKeyID: "3", "price": "¥2,990", "title": "C"},
+		Item{ItemKeyID: "4", "price": "¥10,010", "title": "DD"},
+	}
+	got := (&Differ{Rules: rules}).Compare(list1, list2)
+	ids := make([]string, 0)
+	for _, u := range got {
+		ids = append(ids, u.ItemID())
+	}
+	if want := []string{"2", "3", "4"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Differ.Compare() ids = %v, want %v", ids, want)
+	}
+	if _, exist := got[2].Change.ChangedKeys["price"]; exist {
+		t.Error("price change below the threshold should be dropped")
+	}
+	if _, exist := got[2].Change.ChangedKeys["title"]; !exist {
+		t.Error("title change should be kept")
+	}
+}
+
+func TestChangeRule_Match(t *testing.T) {
+	ten := 10.
+	tests := []struct {
+		name     string
+		rule     ChangeRule
+		old, new float64
+		want     bool
+	}{
+		{name: "absolute", rule: ChangeRule{Absolute: &ten}, old: 100, new: 110, want: true},
+		{name: "absolute under", rule: ChangeRule{Absolute: &ten}, old: 100, new: 109},
+		{name: "increase only", rule: ChangeRule{Direction: DirectionIncrease}, old: 100, new: 90},
+		{name: "decrease only", rule: ChangeRule{Direction: DirectionDecrease}, old: 100, new: 90, want: true},
+		{name: "above", rule: ChangeRule{Above: &ten}, old: 5, new: 11, want: true},
+		{name: "cross up", rule: ChangeRule{Cross: &ten}, old: 5, new: 11, want: true},
+		{name: "cross down", rule: ChangeRule{Cross: &ten}, old: 11, new: 5, want: true},
+		{name: "not cross", rule: ChangeRule{Cross: &ten}, old: 11, new: 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Match(tt.old, tt.new); got != tt.want {
+				t.Errorf("ChangeRule.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package value
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	TextDiffEqual  TextDiffType = "equal"
+	TextDiffInsert TextDiffType = "insert"
+	TextDiffDelete TextDiffType = "delete"
+
+	ansiReset  = "\x1b[0m"
+	ansiDelete = "\x1b[31;9m"
+	ansiInsert = "\x1b[32m"
+)
+
+// wordPattern splits the text into words.
+// CJK characters are split into each character because they are not separated by spaces.
+var wordPattern = regexp.MustCompile(`(?s)\s+|[\p{Han}\p{Hiragana}\p{Katakana}]|[^\s\p{Han}\p{Hiragana}\p{Katakana}\p{P}\p{S}]+|.`)
+
+type (
+	TextDiffType string
+	// TextDiff is a fragment of the word-level diff.
+	TextDiff struct {
+		Type TextDiffType `json:"type"`
+		Text string       `json:"text"`
+	}
+	// TextDiffs is the word-level diff between 2 strings.
+	TextDiffs []TextDiff
+)
+
+// DiffText computes the word-level diff between 2 strings.
+func DiffText(old, new string) TextDiffs {
+	// encode each word as a rune and diff them as characters
+	words := make([]string, 0)
+	wordToRune := make(map[string]rune)
+	encode := func(s string) string {
+		var b strings.Builder
+		for _, w := range wordPattern.FindAllString(s, -1) {
+			r, exist := wordToRune[w]
+			if !exist {
+				r = wordRune(len(words))
+				wordToRune[w] = r
+				words = append(words, w)
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+	runeToWord := func(r rune) string {
+		if r >= 0xE000 {
+			return words[r-0xE000+0xD800-1]
+		}
+		return words[r-1]
+	}
+	encodedOld := encode(old)
+	encodedNew := encode(new)
+
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffCleanupSemantic(dmp.DiffMain(encodedOld, encodedNew, false))
+	result := make(TextDiffs, 0, len(diffs))
+	for _, d := range diffs {
+		var b strings.Builder
+		for _, r := range d.Text {
+			b.WriteString(runeToWord(r))
+		}
+		var t TextDiffType
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			t = TextDiffInsert
+		case diffmatchpatch.DiffDelete:
+			t = TextDiffDelete
+		default:
+			t = TextDiffEqual
+		}
+		result = append(result, TextDiff{t, b.String()})
+	}
+	return result
+}
+
+// wordRune returns the rune representing the index-th word.
+// Surrogate code points are skipped because they are not valid runes.
+func wordRune(index int) rune {
+	r := rune(index + 1)
+	if r >= 0xD800 {
+		r += 0xE000 - 0xD800
+	}
+	return r
+}
+
+// Diff returns the word-level diff between the old value and the new value.
+func (c ItemValueChange) Diff() TextDiffs {
+	return DiffText(c.Old, c.New)
+}
+
+// Mrkdwn renders the diff as Slack mrkdwn. (~deleted~ *inserted*)
+// Slack formats the markers only if they are separated from the surrounding text,
+// so a space is put between the formatted text and the adjacent text.
+func (d TextDiffs) Mrkdwn() string {
+	var b strings.Builder
+	// formatted is true if the last written text is formatted.
+	formatted := false
+	for _, diff := range d {
+		text := diff.Text
+		switch diff.Type {
+		case TextDiffInsert:
+			text = wrapLines(text, "*", "*")
+		case TextDiffDelete:
+			text = wrapLines(text, "~", "~")
+		}
+		if text == "" {
+			continue
+		}
+		format := diff.Type != TextDiffEqual
+		if (format || formatted) && b.Len() > 0 && !endsWithSpace(b.String()) && !startsWithSpace(text) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+		formatted = format
+	}
+	return b.String()
+}
+
+// ANSI renders the diff with the console colors.
+func (d TextDiffs) ANSI() string {
+	var b strings.Builder
+	for _, diff := range d {
+		switch diff.Type {
+		case TextDiffInsert:
+			b.WriteString(ansiInsert + diff.Text + ansiReset)
+		case TextDiffDelete:
+			b.WriteString(ansiDelete + diff.Text + ansiReset)
+		default:
+			b.WriteString(diff.Text)
+		}
+	}
+	return b.String()
+}
+
+// HTML renders the diff as HTML with <ins> and <del>.
+func (d TextDiffs) HTML() string {
+	var b strings.Builder
+	for _, diff := range d {
+		text := html.EscapeString(diff.Text)
+		switch diff.Type {
+		case TextDiffInsert:
+			b.WriteString("<ins>" + text + "</ins>")
+		case TextDiffDelete:
+			b.WriteString("<del>" + text + "</del>")
+		default:
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+// wrapLines wraps each line of the text with the markers.
+// Leading and trailing spaces are put outside of the markers because Slack doesn't format them.
+func wrapLines(text, open, close string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		start := strings.Index(line, trimmed)
+		lines[i] = line[:start] + open + trimmed + close + line[start+len(trimmed):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(r)
+}
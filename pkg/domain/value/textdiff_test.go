@@ -0,0 +1,80 @@
+package value
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     TextDiffs
+		mrkdwn   string
+		html     string
+	}{
+		{
+			name: "words",
+			old:  "The quick brown fox jumps",
+			new:  "The quick red fox jumps",
+			want: TextDiffs{
+				{TextDiffEqual, "The quick "},
+				{TextDiffDelete, "brown"},
+				{TextDiffInsert, "red"},
+				{TextDiffEqual, " fox jumps"},
+			},
+			mrkdwn: "The quick ~brown~ *red* fox jumps",
+			html:   "The quick <del>brown</del><ins>red</ins> fox jumps",
+		},
+		{
+			name: "japanese",
+			old:  "在庫あり",
+			new:  "在庫なし",
+			want: TextDiffs{
+				{TextDiffEqual, "在庫"},
+				{TextDiffDelete, "あり"},
+				{TextDiffInsert, "なし"},
+			},
+			mrkdwn: "在庫 ~あり~ *なし*",
+			html:   "在庫<del>あり</del><ins>なし</ins>",
+		},
+		{
+			name: "spaces outside of markers",
+			old:  "a",
+			new:  "a b <c>\nd",
+			want: TextDiffs{
+				{TextDiffEqual, "a"},
+				{TextDiffInsert, " b <c>\nd"},
+			},
+			mrkdwn: "a *b <c>*\n*d*",
+			html:   "a<ins> b &lt;c&gt;\nd</ins>",
+		},
+		{
+			name: "inserted in a word",
+			old:  "価格1000円",
+			new:  "価格900円",
+			want: TextDiffs{
+				{TextDiffEqual, "価格"},
+				{TextDiffDelete, "1000"},
+				{TextDiffInsert, "900"},
+				{TextDiffEqual, "円"},
+			},
+			mrkdwn: "価格 ~1000~ *900* 円",
+			html:   "価格<del>1000</del><ins>900</ins>円",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffText(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffText() = %v, want %v", got, tt.want)
+			}
+			if m := got.Mrkdwn(); m != tt.mrkdwn {
+				t.Errorf("TextDiffs.Mrkdwn() = %q, want %q", m, tt.mrkdwn)
+			}
+			if h := got.HTML(); h != tt.html {
+				t.Errorf("TextDiffs.HTML() = %q, want %q", h, tt.html)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package value
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 )
 
@@ -29,7 +28,7 @@ func (j JSONObject) JSONArray() JSONArray {
 func (j JSONObject) ItemList() ItemList {
 	elm := make(Item)
 	for k, v := range j {
-		elm[k] = fmt.Sprint(v)
+		elm[k] = itemValue(v)
 	}
 	return ItemList{elm}
 }
@@ -71,18 +70,18 @@ func (j JSONArray) ItemList() ItemList {
 		case JSONObject:
 			elm := make(Item)
 			for k, v := range v {
-				elm[k] = fmt.Sprint(v)
+				elm[k] = itemValue(v)
 			}
 			list[i] = elm
 		case map[string]interface{}:
 			elm := make(Item)
 			for k, v := range v {
-				elm[k] = fmt.Sprint(v)
+				elm[k] = itemValue(v)
 			}
 			list[i] = elm
 		default:
-			list[i] = map[string]string{
-				fmt.Sprint(arrayElement): "",
+			list[i] = Item{
+				FormatItemValue(arrayElement): "",
 			}
 		}
 	}
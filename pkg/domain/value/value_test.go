@@ -20,8 +20,8 @@ func TestItemList(t *testing.T) {
 			want: ItemList{
 				Item{
 					"a": "A",
-					"b": "1",
-					"c": "true",
+					"b": 1,
+					"c": true,
 				},
 			},
 		},
@@ -36,7 +36,7 @@ func TestItemList(t *testing.T) {
 			want: ItemList{
 				Item{
 					"a": "A",
-					"b": "1",
+					"b": 1,
 					"c": "map[d:1]",
 				},
 			},
@@ -57,13 +57,13 @@ func TestItemList(t *testing.T) {
 			want: ItemList{
 				Item{
 					"a": "A",
-					"b": "1",
-					"c": "true",
+					"b": 1,
+					"c": true,
 				},
 				Item{
 					"a": "AA",
-					"b": "2",
-					"c": "false",
+					"b": 2,
+					"c": false,
 				},
 			},
 		},
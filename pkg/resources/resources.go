@@ -15,3 +15,15 @@ var SlackArrayTemplateRemove string
 
 //go:embed templates/slack-array-change.json
 var SlackArrayTemplateChange string
+
+//go:embed templates/slack-array-move.json
+var SlackArrayTemplateMove string
+
+//go:embed templates/slack-notification.json
+var SlackNotificationTemplate string
+
+//go:embed templates/slack-failure.json
+var SlackFailureTemplate string
+
+//go:embed templates/slack-recovery.json
+var SlackRecoveryTemplate string
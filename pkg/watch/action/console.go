@@ -2,7 +2,10 @@ package action
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
 )
 
 type (
@@ -22,7 +25,9 @@ func (s *ConsoleAction) Run(ctx *domain.JobContext, res *domain.Result) error {
 	fmt.Println("--------------------------------------------------------------------------------")
 	if updates.Changes() {
 		fmt.Println("Changes:")
-		fmt.Println(updates)
+		for _, update := range updates {
+			printUpdate(update)
+		}
 		fmt.Println()
 		fmt.Println("Previous:")
 		fmt.Println(res.Previous)
@@ -35,3 +40,38 @@ func (s *ConsoleAction) Run(ctx *domain.JobContext, res *domain.Result) error {
 	}
 	return nil
 }
+
+func printUpdate(update value.Update) {
+	switch update.Type {
+	case value.UpdateTypeAdd:
+		fmt.Printf("+ %s\n", update.Add.JSON())
+	case value.UpdateTypeRemove:
+		fmt.Printf("- %s\n", update.Remove.JSON())
+	case value.UpdateTypeMove:
+		fmt.Printf("> %s: #%d -> #%d\n", update.ItemID(), update.Move.From+1, update.Move.To+1)
+	case value.UpdateTypeChange:
+		fmt.Printf("~ %s\n", update.ItemID())
+		keys := make([]string, 0)
+		for k := range update.Change.ChangedKeys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("    %s: %s\n", k, update.Change.ChangedKeys[k].Diff().ANSI())
+		}
+		for k, v := range update.Change.AddedKeys {
+			fmt.Printf("    +%s: %s\n", k, v)
+		}
+		for k, v := range update.Change.RemovedKeys {
+			fmt.Printf("    -%s: %s\n", k, v)
+		}
+	}
+}
+
+func (s *ConsoleAction) Notify(ctx *domain.JobContext, notification *domain.Notification) error {
+	fmt.Println("--------------------------------------------------------------------------------")
+	fmt.Printf("[%s] %s (%s)\n", notification.Type, notification.Label, notification.JobID)
+	fmt.Println("--------------------------------------------------------------------------------")
+	fmt.Println(notification.Message)
+	return nil
+}
@@ -67,45 +67,65 @@ func (s *SlackBotAction) Run(ctx *domain.JobContext, res *domain.Result) error {
 				isReply = true
 			}
 		}
-		if s.debug || len(s.token) == 0 {
-			ctx.Log.Info("Slack action is debug mode.  No notification.")
-			payloadBytes, _ := yaml.Marshal(payloadValue)
-			fmt.Println("[Payload]")
-			fmt.Println(string(payloadBytes))
-		} else {
-			payloadBytes, err := json.Marshal(payloadValue)
-			if err != nil {
-				return err
-			}
-			post, err := http.NewRequest("POST", "https://slack.com/api/chat.postMessage", bytes.NewReader(payloadBytes))
-			if err != nil {
-				return err
-			}
-			post.Header.Add("Content-Type", "application/json; charset=UTF-8")
-			post.Header.Add("Authorization", "Bearer "+s.token)
-			resp, err := http.DefaultClient.Do(post)
-			if err != nil {
-				return err
-			}
-			defer resp.Body.Close()
-			b, _ := ioutil.ReadAll(resp.Body)
-			if resp.StatusCode != 200 {
-				return fmt.Errorf("invalid status code: status=%d, body=%s", resp.StatusCode, string(b))
-			}
-			var response slackBotResponse
-			if err := json.Unmarshal(b, &response); err != nil {
-				return fmt.Errorf("failed to unmarshal slack api response: err=%v, body=%s", err, string(b))
-			}
-			threadTS := response.TS
-			if len(threadTS) > 0 && s.repo != nil && !isReply { // if this message is posted to thread, then no need to save timestamp
-				if err := s.repo.PutSlackThreadTS(res.JobID, update.ItemID(), threadTS); err != nil {
-					ctx.Log.WithFields(logrus.Fields{
-						"jobID":  res.JobID,
-						"itemID": update.ItemID(),
-					}).Warn("failed to put slack thread_ts into repository.")
-				}
+		threadTS, err := s.post(ctx, payloadValue)
+		if err != nil {
+			return err
+		}
+		if len(threadTS) > 0 && s.repo != nil && !isReply { // if this message is posted to thread, then no need to save timestamp
+			if err := s.repo.PutSlackThreadTS(res.JobID, update.ItemID(), threadTS); err != nil {
+				ctx.Log.WithFields(logrus.Fields{
+					"jobID":  res.JobID,
+					"itemID": update.ItemID(),
+				}).Warn("failed to put slack thread_ts into repository.")
 			}
 		}
 	}
 	return nil
 }
+
+func (s *SlackBotAction) Notify(ctx *domain.JobContext, notification *domain.Notification) error {
+	payloadValue, err := slackNotificationPayload(ctx, notification)
+	if err != nil {
+		return err
+	}
+	payloadValue["channel"] = s.channel
+	payloadValue["unfurl_links"] = false
+	payloadValue["unfurl_media"] = false
+	_, err = s.post(ctx, payloadValue)
+	return err
+}
+
+// post posts the message and returns the timestamp of the message.
+func (s *SlackBotAction) post(ctx *domain.JobContext, payloadValue map[string]interface{}) (string, error) {
+	if s.debug || len(s.token) == 0 {
+		ctx.Log.Info("Slack action is debug mode.  No notification.")
+		payloadBytes, _ := yaml.Marshal(payloadValue)
+		fmt.Println("[Payload]")
+		fmt.Println(string(payloadBytes))
+		return "", nil
+	}
+	payloadBytes, err := json.Marshal(payloadValue)
+	if err != nil {
+		return "", err
+	}
+	post, err := http.NewRequestWithContext(ctx.Context(), "POST", "https://slack.com/api/chat.postMessage", bytes.NewReader(payloadBytes))
+	if err != nil {
+		return "", err
+	}
+	post.Header.Add("Content-Type", "application/json; charset=UTF-8")
+	post.Header.Add("Authorization", "Bearer "+s.token)
+	resp, err := http.DefaultClient.Do(post)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("invalid status code: status=%d, body=%s", resp.StatusCode, string(b))
+	}
+	var response slackBotResponse
+	if err := json.Unmarshal(b, &response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal slack api response: err=%v, body=%s", err, string(b))
+	}
+	return response.TS, nil
+}
@@ -59,3 +59,14 @@ func compareYAML(t *testing.T, actual, expected interface{}) {
 	}
 	t.Errorf("---Expected---\n%s\n---Actual---\n%s", string(expectedBytes), string(actualBytes))
 }
+
+func TestSlackNotificationPayload(t *testing.T) {
+	ctx := domain.NewDefaultJobContext()
+	for _, notificationType := range []domain.NotificationType{domain.NotificationTypeGuard, domain.NotificationTypeFailure, domain.NotificationTypeRecovery} {
+		notification := domain.NewNotification(&domain.JobInfo{ID: "job", Label: `"label"`}, notificationType, "failed to fetch: \"unavailable\"\n")
+		notification.Failures = 3
+		if _, err := slackNotificationPayload(ctx, notification); err != nil {
+			t.Errorf("slackNotificationPayload(%s) error = %v", notificationType, err)
+		}
+	}
+}
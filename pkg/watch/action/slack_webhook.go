@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ghodss/yaml"
 	"github.com/uphy/watch-web/pkg/domain/template"
@@ -17,6 +18,11 @@ import (
 	"github.com/uphy/watch-web/pkg/resources"
 )
 
+const (
+	// inlineDiffMinLength is the minimum length of the value to show the change as the inline diff.
+	inlineDiffMinLength = 40
+)
+
 type (
 	SlackWebhookAction struct {
 		URL   string
@@ -42,30 +48,50 @@ func (s *SlackWebhookAction) Run(ctx *domain.JobContext, res *domain.Result) err
 			return nil
 		}
 
-		if s.Debug || len(s.URL) == 0 {
-			ctx.Log.Info("Slack action is debug mode.  No notification.")
-			payloadBytes, _ := yaml.Marshal(payloadValue)
-			fmt.Println("[Payload]")
-			fmt.Println(string(payloadBytes))
-		} else {
-			payloadBytes, err := json.Marshal(payloadValue)
-			if err != nil {
-				return err
-			}
-			resp, err := http.Post(s.URL, "application/json", bytes.NewReader(payloadBytes))
-			if err != nil {
-				return err
-			}
-			defer resp.Body.Close()
-			b, _ := ioutil.ReadAll(resp.Body)
-			if resp.StatusCode != 200 {
-				return fmt.Errorf("invalid status code: status=%d, body=%s", resp.StatusCode, string(b))
-			}
+		if err := s.post(ctx, payloadValue); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+func (s *SlackWebhookAction) Notify(ctx *domain.JobContext, notification *domain.Notification) error {
+	payloadValue, err := slackNotificationPayload(ctx, notification)
+	if err != nil {
+		return err
+	}
+	return s.post(ctx, payloadValue)
+}
+
+func (s *SlackWebhookAction) post(ctx *domain.JobContext, payloadValue map[string]interface{}) error {
+	if s.Debug || len(s.URL) == 0 {
+		ctx.Log.Info("Slack action is debug mode.  No notification.")
+		payloadBytes, _ := yaml.Marshal(payloadValue)
+		fmt.Println("[Payload]")
+		fmt.Println(string(payloadBytes))
+		return nil
+	}
+	payloadBytes, err := json.Marshal(payloadValue)
+	if err != nil {
+		return err
+	}
+	post, err := http.NewRequestWithContext(ctx.Context(), "POST", s.URL, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return err
+	}
+	post.Header.Add("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(post)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("invalid status code: status=%d, body=%s", resp.StatusCode, string(b))
+	}
+	return nil
+}
+
 func slackPayload(ctx *domain.JobContext, res *domain.Result, update value.Update) (map[string]interface{}, error) {
 	var tmpl *template.Template
 	args := map[string]interface{}{
@@ -80,23 +106,16 @@ func slackPayload(ctx *domain.JobContext, res *domain.Result, update value.Updat
 		} else {
 			tmpl, err = template.Parse(resources.SlackArrayTemplateRemove)
 		}
-		fields := make([]map[string]string, 0)
-		for k, v := range item {
-			if k == "link" || k == "summary" || k == "thumbnail" || k == "id" {
-				continue
-			}
-			fields = append(fields, map[string]string{
-				"key":   k,
-				"value": v,
-			})
-		}
-		sort.Slice(fields, func(i, j int) bool {
-			k1 := fields[i]["key"]
-			k2 := fields[j]["key"]
-			return strings.Compare(k1, k2) < 0
-		})
-		args["fields"] = fields
+		args["fields"] = itemFields(item)
 		args["item"] = update.Item().(value.Item)
+	// move
+	case *value.ItemMove:
+		tmpl, err = template.Parse(resources.SlackArrayTemplateMove)
+		args["fields"] = itemFields(item.Item)
+		args["item"] = item.Item
+		// ranks are 1-origin
+		args["from"] = item.From + 1
+		args["to"] = item.To + 1
 	// change
 	case *value.ItemChange:
 		tmpl, err = template.Parse(resources.SlackArrayTemplateChange)
@@ -109,12 +128,18 @@ func slackPayload(ctx *domain.JobContext, res *domain.Result, update value.Updat
 			})
 		}
 		for k, v := range item.ChangedKeys {
-			fields = append(fields, map[string]string{
+			field := map[string]string{
 				"type": "change",
 				"key":  k,
 				"old":  v.Old,
 				"new":  v.New,
-			})
+				"diff": v.Diff().Mrkdwn(),
+			}
+			// long values are shown as the inline diff not to show 2 near-identical paragraphs
+			if utf8.RuneCountInString(v.Old) >= inlineDiffMinLength {
+				field["inline"] = "true"
+			}
+			fields = append(fields, field)
 		}
 		for k, v := range item.RemovedKeys {
 			fields = append(fields, map[string]string{
@@ -136,6 +161,59 @@ func slackPayload(ctx *domain.JobContext, res *domain.Result, update value.Updat
 		return nil, fmt.Errorf("failed to parse template: %v", err)
 	}
 
+	return evaluatePayload(ctx, tmpl, args)
+}
+
+// itemFields returns the fields of the item except for the ones shown in the message header.
+func itemFields(item value.Item) []map[string]string {
+	fields := make([]map[string]string, 0)
+	for k, v := range item {
+		if k == "link" || k == "summary" || k == "thumbnail" || k == "id" {
+			continue
+		}
+		fields = append(fields, map[string]string{
+			"key":   k,
+			"value": value.FormatItemValue(v),
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		k1 := fields[i]["key"]
+		k2 := fields[j]["key"]
+		return strings.Compare(k1, k2) < 0
+	})
+	return fields
+}
+
+func slackNotificationPayload(ctx *domain.JobContext, notification *domain.Notification) (map[string]interface{}, error) {
+	source := resources.SlackNotificationTemplate
+	switch notification.Type {
+	case domain.NotificationTypeFailure:
+		source = resources.SlackFailureTemplate
+	case domain.NotificationTypeRecovery:
+		source = resources.SlackRecoveryTemplate
+	}
+	tmpl, err := template.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+	title, color := notificationStyle(notification.Type)
+	return evaluatePayload(ctx, tmpl, map[string]interface{}{
+		"notification": notification,
+		"title":        title,
+		"color":        color,
+	})
+}
+
+func notificationStyle(notificationType domain.NotificationType) (title string, color string) {
+	switch notificationType {
+	case domain.NotificationTypeGuard:
+		return "Invalid Value", "#FF8000"
+	default:
+		return string(notificationType), "#808080"
+	}
+}
+
+func evaluatePayload(ctx *domain.JobContext, tmpl *template.Template, args map[string]interface{}) (map[string]interface{}, error) {
 	templateContext := template.NewRootTemplateContext()
 	for k, v := range args {
 		templateContext.Set(k, v)
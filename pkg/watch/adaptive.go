@@ -0,0 +1,81 @@
+package watch
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain"
+)
+
+const (
+	// DefaultAdaptiveBackoff is the default multiplier of the interval while the job is unchanged.
+	DefaultAdaptiveBackoff = 2.
+)
+
+type (
+	// AdaptiveSchedule adjusts the interval of the job between MinInterval and MaxInterval.
+	// The interval is reset to MinInterval when the job is changed and multiplied by Backoff while unchanged.
+	// The current interval is stored in domain.JobStatus to survive restarts.
+	AdaptiveSchedule struct {
+		MinInterval time.Duration
+		MaxInterval time.Duration
+		// Backoff is the multiplier of the interval while the job is unchanged.  0 means DefaultAdaptiveBackoff.
+		Backoff float64
+
+		mu sync.Mutex
+		// due is the time the next check should run.
+		due time.Time
+	}
+)
+
+// Next implements cron.Schedule.
+// The schedule is activated at MinInterval at the latest because the due time is updated after the check.
+func (a *AdaptiveSchedule) Next(t time.Time) time.Time {
+	next := t.Add(a.MinInterval)
+	if due := a.Due(); due.After(next) {
+		next = due
+	}
+	return next
+}
+
+// Due returns the time the next check should run.
+func (a *AdaptiveSchedule) Due() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.due
+}
+
+// restore restores the due time from the status stored by the previous check.
+func (a *AdaptiveSchedule) restore(status *domain.JobStatus) {
+	if status == nil || status.Last == nil || status.Interval <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.due = status.Last.Add(time.Duration(status.Interval * float64(time.Second)))
+}
+
+// update adjusts the interval by the result of the check started at now.
+func (a *AdaptiveSchedule) update(status *domain.JobStatus, changed bool, now time.Time) {
+	interval := time.Duration(status.Interval * float64(time.Second))
+	if changed || interval <= 0 {
+		interval = a.MinInterval
+	} else {
+		backoff := a.Backoff
+		if backoff <= 0 {
+			backoff = DefaultAdaptiveBackoff
+		}
+		interval = time.Duration(float64(interval) * backoff)
+	}
+	if interval > a.MaxInterval {
+		interval = a.MaxInterval
+	}
+	if interval < a.MinInterval {
+		interval = a.MinInterval
+	}
+	status.Interval = interval.Seconds()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.due = now.Add(interval)
+}
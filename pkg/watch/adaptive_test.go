@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func TestExecutor_CheckAdaptive(t *testing.T) {
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	one := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}})
+	two := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}})
+	job := NewJob(&domain.JobInfo{ID: "job"}, &sequenceSource{values: []value.Value{one, one, one, one, two, two}}, nil)
+	job.Adaptive = &AdaptiveSchedule{MinInterval: time.Minute, MaxInterval: 5 * time.Minute}
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	// first check, unchanged, unchanged, unchanged(max), changed, unchanged
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, time.Minute, 2 * time.Minute}
+	for i, w := range want {
+		start := time.Now()
+		if _, err := e.Check(job); err != nil {
+			t.Fatal(err)
+		}
+		status, _ := e.GetJobStatus("job")
+		if got := time.Duration(status.Interval * float64(time.Second)); got != w {
+			t.Errorf("#%d interval = %v, want %v", i, got, w)
+		}
+		if due := job.Adaptive.Due(); due.Before(start.Add(w)) || due.After(time.Now().Add(w)) {
+			t.Errorf("#%d due = %v, want %v later", i, due, w)
+		}
+	}
+
+	// the due time survives restarts
+	restarted := &AdaptiveSchedule{MinInterval: time.Minute, MaxInterval: 5 * time.Minute}
+	job2 := NewJob(&domain.JobInfo{ID: "job"}, job.source, nil)
+	job2.Adaptive = restarted
+	if err := NewExecutor(s, logrus.New()).AddJob(job2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted.Due().Equal(job.Adaptive.Due()) {
+		t.Errorf("restored due = %v, want %v", restarted.Due(), job.Adaptive.Due())
+	}
+}
+
+func TestAdaptiveSchedule_Next(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	a := &AdaptiveSchedule{MinInterval: time.Minute, MaxInterval: time.Hour}
+	if got := a.Next(now); !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("Next() = %v, want MinInterval later", got)
+	}
+	a.due = now.Add(30 * time.Minute)
+	if got := a.Next(now); !got.Equal(a.due) {
+		t.Errorf("Next() = %v, want %v", got, a.due)
+	}
+}
@@ -0,0 +1,41 @@
+package watch
+
+import (
+	"fmt"
+
+	"github.com/uphy/watch-web/pkg/domain"
+)
+
+// alert counts the consecutive failures of the check and notifies the failure once they reach AlertAfter.
+// After the failure is notified, the recovery is notified once when the check succeeds.
+func (e *Executor) alert(job *Job, status *domain.JobStatus) {
+	if status.Status != domain.StatusError {
+		failures := status.Failures
+		status.Failures = 0
+		if !status.FailureNotified {
+			return
+		}
+		status.FailureNotified = false
+		notification := domain.NewNotification(job.Info, domain.NotificationTypeRecovery, fmt.Sprintf("Recovered after %d failures.", failures))
+		notification.Failures = failures
+		if err := e.Notify(job, notification); err != nil {
+			job.ctx.Log.WithField("err", err).Warn("Failed to notify recovery.")
+		}
+		return
+	}
+
+	status.Failures++
+	if job.AlertAfter <= 0 || status.Failures < job.AlertAfter || status.FailureNotified {
+		return
+	}
+	status.FailureNotified = true
+	msg := "unknown error"
+	if status.Error != nil {
+		msg = *status.Error
+	}
+	notification := domain.NewNotification(job.Info, domain.NotificationTypeFailure, msg)
+	notification.Failures = status.Failures
+	if err := e.Notify(job, notification); err != nil {
+		job.ctx.Log.WithField("err", err).Warn("Failed to notify failure.")
+	}
+}
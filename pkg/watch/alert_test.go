@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+// failingSource fails while fail is true.
+type failingSource struct {
+	fail bool
+}
+
+func (s *failingSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
+	if s.fail {
+		return nil, errors.New("unavailable")
+	}
+	return value.NewJSONArray(nil), nil
+}
+
+// notificationRecorder records the notifications.
+type notificationRecorder struct {
+	notifications []*domain.Notification
+}
+
+func (r *notificationRecorder) Run(ctx *domain.JobContext, result *domain.Result) error {
+	return nil
+}
+
+func (r *notificationRecorder) Notify(ctx *domain.JobContext, notification *domain.Notification) error {
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func TestExecutor_CheckAlert(t *testing.T) {
+	e := NewExecutor(store.NewMemoryStore(), logrus.New())
+	src := new(failingSource)
+	recorder := new(notificationRecorder)
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, []domain.Action{recorder})
+	job.AlertAfter = 2
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		fail     bool
+		failures int
+		notified domain.NotificationType
+	}{
+		{true, 1, ""},
+		{false, 0, ""},
+		{true, 1, ""},
+		{true, 2, domain.NotificationTypeFailure},
+		{true, 3, ""},
+		{false, 0, domain.NotificationTypeRecovery},
+		{false, 0, ""},
+	}
+	for i, tt := range tests {
+		src.fail = tt.fail
+		recorder.notifications = nil
+		e.Check(job)
+		status, _ := e.GetJobStatus("job")
+		if status.Failures != tt.failures {
+			t.Errorf("#%d failures = %d, want %d", i, status.Failures, tt.failures)
+		}
+		var notified domain.NotificationType
+		if len(recorder.notifications) > 0 {
+			notified = recorder.notifications[0].Type
+		}
+		if len(recorder.notifications) > 1 || notified != tt.notified {
+			t.Errorf("#%d notifications = %v, want %s", i, recorder.notifications, tt.notified)
+		}
+	}
+}
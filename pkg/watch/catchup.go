@@ -0,0 +1,53 @@
+package watch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+// OverdueJobs returns the scheduled jobs whose next run computed from the last check is not after now.
+// The jobs never checked are also overdue.
+// The jobs late more than maxLateness are excluded.  0 means no limit.
+func (e *Executor) OverdueJobs(now time.Time, maxLateness time.Duration) ([]*Job, error) {
+	overdue := make([]*Job, 0)
+	for _, job := range e.Jobs() {
+		if job.schedule == nil {
+			continue
+		}
+		status, err := e.store.GetJobStatus(job.ID())
+		if err != nil && err != store.ErrNotFound {
+			return nil, fmt.Errorf("failed to get job status: job=%s, err=%w", job.ID(), err)
+		}
+		if status == nil || status.Last == nil {
+			overdue = append(overdue, job)
+			continue
+		}
+		due := job.schedule.Next(*status.Last)
+		if due.IsZero() || due.After(now) {
+			continue
+		}
+		if maxLateness > 0 && now.Sub(due) > maxLateness {
+			continue
+		}
+		overdue = append(overdue, job)
+	}
+	return overdue, nil
+}
+
+// catchUp checks the overdue jobs.
+// The jobs late more than MaxLateness are excluded because the next scheduled run is near enough to them.
+func (e *Executor) catchUp() {
+	jobs, err := e.OverdueJobs(time.Now(), e.MaxLateness)
+	if err != nil {
+		e.log.WithField("err", err).Error("Failed to find overdue jobs.")
+		return
+	}
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID()
+	}
+	e.log.WithField("jobs", ids).Info("Catching up missed runs.")
+	e.checkJobs(jobs, true)
+}
@@ -0,0 +1,56 @@
+package watch
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/source"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func TestExecutor_OverdueJobs(t *testing.T) {
+	now := time.Now()
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	tests := []struct {
+		id       string
+		schedule string
+		adaptive *AdaptiveSchedule
+		last     time.Duration
+		interval float64
+	}{
+		{id: "never", schedule: "@every 1h"},
+		{id: "not-scheduled", last: 3 * time.Hour},
+		{id: "on-time", schedule: "@every 1h", last: 30 * time.Minute},
+		{id: "overdue", schedule: "@every 1h", last: 90 * time.Minute},
+		{id: "too-late", schedule: "@every 1h", last: 4 * time.Hour},
+		{id: "adaptive-on-time", adaptive: &AdaptiveSchedule{MinInterval: time.Minute, MaxInterval: time.Hour}, last: 10 * time.Minute, interval: 1800},
+		{id: "adaptive-overdue", adaptive: &AdaptiveSchedule{MinInterval: time.Minute, MaxInterval: time.Hour}, last: 40 * time.Minute, interval: 1800},
+	}
+	for _, tt := range tests {
+		if tt.last > 0 {
+			last := now.Add(-tt.last)
+			s.SetJobStatus(tt.id, &domain.JobStatus{Status: domain.StatusOK, Last: &last, Interval: tt.interval})
+		}
+		job := NewJob(&domain.JobInfo{ID: tt.id}, source.NewConstantSource(value.NewJSONArray(nil)), nil)
+		job.Adaptive = tt.adaptive
+		if err := e.AddJob(job, &tt.schedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs, err := e.OverdueJobs(now, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID()
+	}
+	if want := []string{"adaptive-overdue", "never", "overdue"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("OverdueJobs() = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,50 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+)
+
+// confirm splits the updates into the confirmed ones and the unconfirmed ones with the pending updates in the status.
+// An update is confirmed when it is observed in ConfirmAfter consecutive checks.
+// The pending updates not observed in this check are discarded.
+func (j *Job) confirm(status *domain.JobStatus, updates value.Updates, now time.Time) (confirmed, unconfirmed value.Updates) {
+	if j.ConfirmAfter <= 1 {
+		status.Pending = nil
+		return updates, nil
+	}
+	previous := make(map[string]domain.PendingUpdate, len(status.Pending))
+	for _, p := range status.Pending {
+		previous[p.Key] = p
+	}
+	pending := make([]domain.PendingUpdate, 0)
+	confirmed = make(value.Updates, 0)
+	unconfirmed = make(value.Updates, 0)
+	for _, u := range updates {
+		if u.Type == value.UpdateTypeMove {
+			// moves don't change the items
+			confirmed = append(confirmed, u)
+			continue
+		}
+		key := j.Differ.UpdateKey(u)
+		p, exist := previous[key]
+		if !exist {
+			p = domain.PendingUpdate{Key: key, Since: now}
+		}
+		p.Update = u
+		p.Count++
+		if p.Count >= j.ConfirmAfter {
+			confirmed = append(confirmed, u)
+			continue
+		}
+		pending = append(pending, p)
+		unconfirmed = append(unconfirmed, u)
+	}
+	if len(pending) == 0 {
+		pending = nil
+	}
+	status.Pending = pending
+	return confirmed, unconfirmed
+}
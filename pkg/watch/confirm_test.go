@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+type sequenceSource struct {
+	values []value.Value
+	index  int
+}
+
+func (s *sequenceSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
+	v := s.values[s.index]
+	s.index++
+	return v, nil
+}
+
+func TestExecutor_CheckConfirmAfter(t *testing.T) {
+	s := store.NewMemoryStore()
+	previous := `[{"id":"1"}]`
+	s.SetJobValue("job", previous)
+	e := NewExecutor(s, logrus.New())
+	one := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}})
+	two := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}})
+	job := NewJob(&domain.JobInfo{ID: "job"}, &sequenceSource{values: []value.Value{two, one, two, two}}, nil)
+	job.ConfirmAfter = 2
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		updates int
+		pending int
+		stored  string
+	}{
+		{"first observation", 0, 1, previous},
+		{"flapped back", 0, 0, previous},
+		{"observed again", 0, 1, previous},
+		{"confirmed", 1, 0, `[{"id":"1"},{"id":"2"}]`},
+	}
+	for _, tt := range tests {
+		res, err := e.Check(job)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := len(res.Diff()); got != tt.updates {
+			t.Errorf("%s: updates = %d, want %d", tt.name, got, tt.updates)
+		}
+		status, _ := s.GetJobStatus("job")
+		if got := len(status.Pending); got != tt.pending {
+			t.Errorf("%s: pending = %d, want %d", tt.name, got, tt.pending)
+		}
+		if v, _ := s.GetJobValue("job"); v != tt.stored {
+			t.Errorf("%s: stored value = %s, want %s", tt.name, v, tt.stored)
+		}
+	}
+}
+
+func TestExecutor_CheckConfirmAfterStructural(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetJobValue("job", `{"a":1,"b":1}`)
+	e := NewExecutor(s, logrus.New())
+	obj := func(a, b int) value.Value {
+		return value.NewJSONObject(map[string]interface{}{"a": a, "b": b})
+	}
+	// "a" keeps flapping while "b" is changed stably.
+	job := NewJob(&domain.JobInfo{ID: "job"}, &sequenceSource{values: []value.Value{obj(2, 2), obj(3, 2), obj(1, 3)}}, nil)
+	job.Differ = &value.Differ{Engine: value.DiffEngineJSON}
+	job.ConfirmAfter = 2
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		updates []string
+		stored  string
+	}{
+		{"first observation", nil, `{"a":1,"b":1}`},
+		{"b confirmed", []string{"/b"}, `{"a":1,"b":2}`},
+		{"a flapped back", nil, `{"a":1,"b":2}`},
+	}
+	for _, tt := range tests {
+		res, err := e.Check(job)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var paths []string
+		for _, u := range res.Diff() {
+			paths = append(paths, u.ItemID())
+		}
+		if !reflect.DeepEqual(paths, tt.updates) {
+			t.Errorf("%s: updates = %v, want %v", tt.name, paths, tt.updates)
+		}
+		if v, _ := s.GetJobValue("job"); v != tt.stored {
+			t.Errorf("%s: stored value = %s, want %s", tt.name, v, tt.stored)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package watch
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,11 +23,46 @@ import (
 
 type (
 	Executor struct {
-		c          *cron.Cron
-		Jobs       map[string]*Job
 		InitialRun bool
-		store      domain.Store
-		log        *logrus.Logger
+		// CatchUp checks the jobs which missed the scheduled runs while the executor was not running.
+		CatchUp bool
+		// MaxLateness is the limit of the delay of the missed runs to catch up.  0 means no limit.
+		MaxLateness time.Duration
+		// Pool limits the concurrent checks triggered by both of the schedule and the API.
+		Pool *WorkerPool
+		// DistributedLock locks the running jobs with the store to prevent the overlapping runs across the instances.
+		DistributedLock bool
+		// LockTTL is the expiration of the lock in the store.
+		LockTTL time.Duration
+		// Leader runs the schedule only while this instance is the leader.  nil means always.
+		Leader *LeaderElector
+		// History is the retention of the run history of the jobs.
+		History domain.HistoryRetention
+		// ShutdownGracePeriod is the time to wait for the checks cancelled by Shutdown.  0 means DefaultShutdownGracePeriod.
+		ShutdownGracePeriod time.Duration
+		store               domain.Store
+		log                 *logrus.Logger
+
+		// jobsMu guards the jobs and the schedule which are replaced by ReplaceJobs.
+		jobsMu sync.RWMutex
+		c      *cron.Cron
+		jobs   map[string]*Job
+		// scheduling is true while the schedule is running.
+		scheduling bool
+		// stopped is closed by Stop.
+		stopped chan struct{}
+		// ctx is the parent of the contexts of the checks, which is cancelled when Shutdown times out.
+		ctx    context.Context
+		cancel context.CancelFunc
+
+		mu sync.Mutex
+		// closed is true after the shutdown started.
+		closed bool
+		// running are the checks in progress.
+		running sync.WaitGroup
+		// electedOnce runs the initial checks only on the first election.
+		electedOnce sync.Once
+		metrics     *metrics
 	}
 )
 
@@ -31,64 +71,315 @@ func init() {
 }
 
 func NewExecutor(store domain.Store, log *logrus.Logger) *Executor {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Executor{
-		c:     cron.New(),
-		store: store,
-		Jobs:  make(map[string]*Job),
-		log:   log,
+		c:       cron.New(),
+		store:   store,
+		jobs:    make(map[string]*Job),
+		stopped: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		Pool:    NewWorkerPool(DefaultWorkers),
+		log:     log,
+		metrics: newMetrics(),
 	}
 }
 
+// Store returns the store of the job values and statuses.
+func (e *Executor) Store() domain.Store {
+	return e.store
+}
+
 func (e *Executor) Job(id string) *Job {
-	return e.Jobs[id]
+	e.jobsMu.RLock()
+	defer e.jobsMu.RUnlock()
+	return e.jobs[id]
+}
+
+// Jobs returns all jobs sorted by ID.
+func (e *Executor) Jobs() []*Job {
+	e.jobsMu.RLock()
+	defer e.jobsMu.RUnlock()
+	jobs := make([]*Job, 0, len(e.jobs))
+	for _, job := range e.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID() < jobs[j].ID()
+	})
+	return jobs
 }
 
 func (e *Executor) AddJob(job *Job, schedule *string) error {
+	if schedule != nil {
+		job.Schedule = *schedule
+	}
+	e.initJob(job)
+	e.jobsMu.Lock()
+	defer e.jobsMu.Unlock()
+	if job.scheduled() {
+		if err := e.scheduleJob(e.c, job); err != nil {
+			return err
+		}
+	}
+	e.jobs[job.ID()] = job
+	return nil
+}
+
+// ReplaceJobs replaces all jobs and their schedules atomically.
+// The jobs with the same ID as the current ones take over the run lock not to overlap with the running checks.
+// The values and the statuses in the store are kept because they are identified by the job ID.
+// If any schedule is invalid, returns the error without replacing the jobs.
+func (e *Executor) ReplaceJobs(jobs []*Job) error {
+	c := cron.New()
+	next := make(map[string]*Job, len(jobs))
+	for _, job := range jobs {
+		if _, exist := next[job.ID()]; exist {
+			return fmt.Errorf("duplicate job id: %s", job.ID())
+		}
+		e.initJob(job)
+		if job.scheduled() {
+			if err := e.scheduleJob(c, job); err != nil {
+				return err
+			}
+		}
+		next[job.ID()] = job
+	}
+
+	e.jobsMu.Lock()
+	defer e.jobsMu.Unlock()
+	var added, removed, replaced []string
+	for id, job := range next {
+		if current, exist := e.jobs[id]; exist {
+			job.lock = current.lock
+			replaced = append(replaced, id)
+		} else {
+			added = append(added, id)
+		}
+	}
+	for id := range e.jobs {
+		if _, exist := next[id]; !exist {
+			removed = append(removed, id)
+		}
+	}
+	current := e.c
+	e.c = c
+	e.jobs = next
+	if e.scheduling {
+		current.Stop()
+		c.Start()
+	}
+	e.metrics.remove(removed...)
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(replaced)
+	e.log.WithFields(logrus.Fields{
+		"added":    added,
+		"removed":  removed,
+		"replaced": replaced,
+	}).Info("Replaced jobs.")
+	return nil
+}
+
+func (e *Executor) initJob(job *Job) {
 	jobLogger := e.log.WithFields(logrus.Fields{
 		"id": job.ID(),
 	})
-	job.ctx = &domain.JobContext{jobLogger}
-	e.Jobs[job.ID()] = job
-	if schedule != nil {
-		return e.c.AddFunc(*schedule, func() {
-			e.Check(job)
-		})
+	job.ctx = &domain.JobContext{Log: jobLogger}
+}
+
+func (e *Executor) scheduleJob(c *cron.Cron, job *Job) error {
+	var schedule cron.Schedule
+	var err error
+	if job.Adaptive != nil {
+		status, serr := e.store.GetJobStatus(job.ID())
+		if serr != nil && serr != store.ErrNotFound {
+			return fmt.Errorf("failed to get job status: job=%s, err=%w", job.ID(), serr)
+		}
+		job.Adaptive.restore(status)
+		schedule, err = applyScheduleOptions(job.Adaptive, job.ScheduleOptions)
+	} else {
+		schedule, err = newJobSchedule(job.Schedule, job.ScheduleOptions)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid schedule: job=%s, schedule=%s, err=%w", job.ID(), job.Schedule, err)
 	}
+	job.schedule = schedule
+	jitter := jitterable(schedule)
+	c.Schedule(schedule, cron.FuncJob(func() {
+		if e.Leader != nil && !e.Leader.IsLeader() {
+			return
+		}
+		// the adaptive schedule may be activated before the due time updated by the last check.
+		if job.Adaptive != nil && time.Now().Before(job.Adaptive.Due()) {
+			return
+		}
+		if delay := job.ScheduleOptions.jitter(); jitter && delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-e.stopped:
+				return
+			}
+		}
+		e.checkJob(job, true)
+	}))
 	return nil
 }
 
+// Run runs the schedule until Stop is called.
 func (e *Executor) Run() {
+	e.jobsMu.Lock()
+	select {
+	case <-e.stopped:
+		e.jobsMu.Unlock()
+		return
+	default:
+	}
+	e.scheduling = true
+	e.c.Start()
+	e.jobsMu.Unlock()
+
+	if e.Leader != nil {
+		// only the leader runs the initial checks.
+		go e.Leader.Run(e.stopped, e.elected)
+	} else {
+		go e.startup()
+	}
+	<-e.stopped
+}
+
+// startup runs the checks on starting the schedule.
+func (e *Executor) startup() {
 	if e.InitialRun {
-		go e.CheckAll()
+		e.checkJobs(e.Jobs(), true)
+	} else if e.CatchUp {
+		e.catchUp()
 	}
+}
 
-	e.c.Run()
+// elected runs the initial checks only on the first election of this instance.
+// On the later elections, only the runs missed while another instance was the leader are caught up.
+func (e *Executor) elected() {
+	first := false
+	e.electedOnce.Do(func() {
+		first = true
+	})
+	if first {
+		e.startup()
+	} else if e.CatchUp {
+		e.catchUp()
+	}
 }
 
+// CheckAll checks all jobs concurrently within the limits of the Pool.
 func (e *Executor) CheckAll() {
-	ch := make(chan struct{}, 1)
+	e.CheckJobs(e.Jobs())
+}
+
+// CheckJobs checks the jobs concurrently within the limits of the Pool.
+// Returns the errors of the jobs in the same order.
+func (e *Executor) CheckJobs(jobs []*Job) []error {
+	return e.checkJobs(jobs, false)
+}
+
+func (e *Executor) checkJobs(jobs []*Job, leaderOnly bool) []error {
+	errs := make([]error, len(jobs))
 	wg := new(sync.WaitGroup)
-	for _, job := range e.Jobs {
+	for i, job := range jobs {
 		wg.Add(1)
-		ch <- struct{}{}
-		go func(job *Job) {
+		go func(i int, job *Job) {
 			defer wg.Done()
-			e.Check(job)
-			<-ch
-		}(job)
+			_, errs[i] = e.checkJob(job, leaderOnly)
+		}(i, job)
 	}
 	wg.Wait()
+	return errs
+}
+
+// Check checks the job.
+// If the job is already running, the job is handled according to its RunPolicy and ErrAlreadyRunning may be returned.
+// ErrShuttingDown is returned after Shutdown is called.
+func (e *Executor) Check(job *Job) (*domain.Result, error) {
+	return e.checkJob(job, false)
+}
+
+// checkJob checks the job.
+// If leaderOnly is true, the check is skipped with ErrNotLeader and nothing is stored if this instance is no longer the leader.
+func (e *Executor) checkJob(job *Job, leaderOnly bool) (res *domain.Result, err error) {
+	if !e.begin() {
+		return nil, ErrShuttingDown
+	}
+	defer e.running.Done()
+
+	run, res, err := job.lock.start(job.RunPolicy)
+	if run == nil {
+		if err == ErrAlreadyRunning {
+			job.ctx.Log.Info("Job is already running.")
+		}
+		return res, err
+	}
+	defer func() {
+		job.lock.finish(run, res, err)
+	}()
+	// the distributed lock is taken after waiting for the pool not to expire while waiting.
+	release, err := e.Pool.Acquire(e.ctx, job.Host())
+	if err != nil {
+		// the check queued when the shutdown started is given up without recording the status.
+		job.ctx.Log.Info("Gave up the job waiting for the pool.")
+		return nil, ErrShuttingDown
+	}
+	defer release()
+
+	unlock, err := e.lockStore(job)
+	if err != nil {
+		if err == ErrAlreadyRunning {
+			job.ctx.Log.Info("Job is already running in another instance.")
+		}
+		return nil, err
+	}
+	defer unlock()
+
+	// the timeout starts after waiting for the pool.
+	ctx := e.ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	return e.check(job, job.ctx.WithContext(ctx), leaderOnly)
 }
 
-func (e *Executor) Check(job *Job) (res *domain.Result, err error) {
+func (e *Executor) check(job *Job, ctx *domain.JobContext, leaderOnly bool) (res *domain.Result, err error) {
+	// lostLeadership returns true if the scheduled check should be left to the new leader.
+	// IsLeader is based on the local clock and is not a real fencing, so a slow check may still
+	// write after the lease expired.  The writes should be guarded by the lease in the store if possible.
+	lostLeadership := func() bool {
+		return leaderOnly && e.Leader != nil && !e.Leader.IsLeader()
+	}
+	if lostLeadership() {
+		job.ctx.Log.Info("Skipped job because this instance is not the leader.")
+		return nil, ErrNotLeader
+	}
 	job.ctx.Log.Info("Running job.")
 
+	run := &domain.JobRun{Start: time.Now()}
+
 	// Get previous job properties
 	status, err := e.store.GetJobStatus(job.ID())
 	if status == nil {
 		status = new(domain.JobStatus)
 	}
+	// skipped is true if the leadership is lost while checking.  Nothing is written not to overwrite the new leader's status.
+	skipped := false
 	defer func() {
+		if skipped {
+			return
+		}
+		e.record(job, run, status)
+		e.metrics.checked(job.ID(), status, time.Since(run.Start))
+		e.alert(job, status)
 		// store status even if got errors for fixing broken data
 		if err := e.store.SetJobStatus(job.ID(), status); err != nil {
 			job.failed(status, "failed to set job status", err)
@@ -108,10 +399,23 @@ func (e *Executor) Check(job *Job) (res *domain.Result, err error) {
 			return nil, err
 		}
 	}
-	previousItemList, err := value.NewItemListFromJSON(previous)
-	if err != nil {
-		job.failed(status, "failed to restore item list", err)
-		previousItemList = make(value.ItemList, 0)
+	var previousValue interface{}
+	var previousItemList value.ItemList
+	if job.Differ.Structural() {
+		// the structural diff engine stores the value in the original typed form.
+		if previousValue, err = restoreJSONValue(previous); err != nil {
+			job.failed(status, "failed to restore previous value", err)
+			return nil, err
+		}
+		previousItemList = value.Auto(previous).ItemList()
+	} else if previousItemList, err = value.NewItemListFromJSON(previous); err != nil {
+		if v, jerr := value.ParseJSON(previous); jerr == nil {
+			// the value stored by the structural diff engine is converted in the same way as the current value.
+			previousItemList = v.ItemList()
+		} else {
+			job.failed(status, "failed to restore item list", err)
+			previousItemList = make(value.ItemList, 0)
+		}
 	}
 
 	now := time.Now()
@@ -119,47 +423,132 @@ func (e *Executor) Check(job *Job) (res *domain.Result, err error) {
 	status.Count++
 	status.Status = domain.StatusRunning
 	status.Error = nil
+	changed := false
+	if job.Adaptive != nil {
+		// failures are regarded as unchanged not to retry too often.
+		defer func() {
+			if !skipped {
+				job.Adaptive.update(status, changed, now)
+			}
+		}()
+	}
 
 	// Get current value
-	current, err := job.source.Fetch(job.ctx)
+	ctx.Timings = new(domain.Timings)
+	fetchStart := time.Now()
+	current, err := job.source.Fetch(ctx)
+	if lostLeadership() {
+		skipped = true
+		job.ctx.Log.Info("Skipped job because the leadership was lost.")
+		return nil, ErrNotLeader
+	}
 	if err != nil {
 		job.failed(status, "failed to fetch", err)
 		return
 	}
+	currentItemList := current.ItemList()
+	run.Items = len(currentItemList)
+	e.metrics.fetched(job.ID(), time.Since(fetchStart), ctx.Timings, len(currentItemList))
 	job.ctx.Log.WithFields(logrus.Fields{
 		"current": fmt.Sprintf("%#v", current),
 	}).Debug("Fetched job result.")
 	job.ctx.Log.Info("Fetched job result.")
-	currentItemList := current.ItemList()
-	currentItemListJSON := currentItemList.JSON()
-	defer func() {
-		if err := e.store.SetJobValue(job.ID(), currentItemListJSON); err != nil {
-			job.failed(status, "failed to store job value", err)
-		}
-	}()
 
 	// make result
 	/*
 	 * previousとcurrentは、ここで確実にItemListになるようにする。
-	 * previousの永続化もItemListで行う。
+	 * previousの永続化もItemListで行う。(構造的な差分エンジンの場合は元の型のまま永続化する)
 	 */
 	res = domain.NewResult(job.Info, previousItemList, currentItemList)
+	res.Differ = job.Differ
+	res.PreviousValue = previousValue
+	res.CurrentValue = current.Interface()
+
+	// Check the value before storing it not to overwrite the previous value with the broken one.
+	if err = job.Guard.Check(previousItemList, currentItemList, res.Diff()); err != nil {
+		job.failed(status, "invalid value", err)
+		e.notifyViolation(job, status, err)
+		return nil, err
+	}
+	status.Violation = nil
+
+	// Keep the previous value for the updates not confirmed yet.
+	if !firstCheck {
+		confirmed, unconfirmed := job.confirm(status, res.Diff(), now)
+		if len(unconfirmed) > 0 {
+			if job.Differ.Structural() {
+				res.CurrentValue = value.DiffJSON(previousValue, res.CurrentValue).Revert(res.CurrentValue, unconfirmed)
+			} else {
+				currentItemList = value.RevertUpdates(previousItemList, currentItemList, unconfirmed)
+			}
+			res.SetUpdates(currentItemList, confirmed)
+		}
+	}
+
+	// Keep the previous values of the changes suppressed by the rules so that the next check compares with the value last reported.
+	if !job.Differ.Structural() {
+		currentItemList = value.RevertKeys(previousItemList, currentItemList, res.Suppressed())
+	}
+
+	currentValueJSON := currentItemList.JSON()
+	if job.Differ.Structural() {
+		b, err := json.Marshal(res.CurrentValue)
+		if err != nil {
+			job.failed(status, "failed to marshal current value", err)
+			return nil, err
+		}
+		currentValueJSON = string(b)
+	}
+	storeValue := true
+	defer func() {
+		if !storeValue {
+			return
+		}
+		if err := e.store.SetJobValue(job.ID(), currentValueJSON); err != nil {
+			job.failed(status, "failed to store job value", err)
+		}
+	}()
+
+	// the new leader checks the job again, so the updates are neither notified nor stored after the leadership is lost.
+	if lostLeadership() {
+		skipped = true
+		storeValue = false
+		job.ctx.Log.Info("Skipped job because the leadership was lost.")
+		return nil, ErrNotLeader
+	}
 
 	// Do action
 	if !firstCheck {
-		if err = e.DoActions(job, res); err != nil {
+		run.Updates = res.Diff()
+		e.metrics.updated(job.ID(), res.Diff())
+		if err = e.doActions(ctx, job, res); err != nil {
+			// the updates are reported again by the next check if the actions are interrupted by the timeout or the shutdown.
+			storeValue = ctx.Context().Err() == nil
 			job.failed(status, "failed to perform action", err)
 			return
 		}
 	}
 
 	// Store job status
+	changed = len(res.Diff()) > 0
 	status.Status = domain.StatusOK
 	job.ctx.Log.WithField("result", fmt.Sprintf("%#v", res)).Debug("Finished job.")
 	job.ctx.Log.Info("Finished job.")
 	return
 }
 
+// restoreJSONValue restores the value stored by the structural diff engine.
+func restoreJSONValue(s string) (interface{}, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
 func (e *Executor) TestActions(job *Job) error {
 	return e.DoActions(job, &domain.Result{
 		JobID:    job.ID(),
@@ -171,15 +560,57 @@ func (e *Executor) TestActions(job *Job) error {
 }
 
 func (e *Executor) DoActions(job *Job, result *domain.Result) error {
+	return e.doActions(job.ctx, job, result)
+}
+
+func (e *Executor) doActions(ctx *domain.JobContext, job *Job, result *domain.Result) error {
 	var errs error
 	for _, action := range job.actions {
-		if err := action.Run(job.ctx, result); err != nil {
+		err := action.Run(ctx, result)
+		e.metrics.sent(job.ID(), err)
+		if err != nil {
 			errs = multierror.Append(errs, err)
 		}
 	}
 	return errs
 }
 
+// notifyViolation sends the guard violation only once until the guard passes again.
+func (e *Executor) notifyViolation(job *Job, status *domain.JobStatus, violation error) {
+	if !job.Guard.Notify || status.Violation != nil {
+		return
+	}
+	msg := violation.Error()
+	status.Violation = &msg
+	if err := e.Notify(job, domain.NewNotification(job.Info, domain.NotificationTypeGuard, msg)); err != nil {
+		job.ctx.Log.WithField("err", err).Warn("Failed to notify guard violation.")
+	}
+}
+
+// Notify sends the notification with the actions which implement domain.Notifier.
+func (e *Executor) Notify(job *Job, notification *domain.Notification) error {
+	var errs error
+	for _, action := range job.actions {
+		notifier, ok := action.(domain.Notifier)
+		if !ok {
+			continue
+		}
+		err := notifier.Notify(job.ctx, notification)
+		e.metrics.sent(job.ID(), err)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+// WriteMetrics writes the metrics of the checks in the Prometheus text exposition format.
+func (e *Executor) WriteMetrics(w io.Writer) error {
+	e.metrics.queueDepth.set(float64(e.Pool.Waiting()))
+	e.metrics.runningChecks.set(float64(e.Pool.Running()))
+	return e.metrics.write(w)
+}
+
 func (e *Executor) GetJobStatus(jobID string) (*domain.JobStatus, error) {
 	status, err := e.store.GetJobStatus(jobID)
 	if err != nil {
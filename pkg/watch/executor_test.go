@@ -0,0 +1,215 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/source"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func TestExecutor_CheckStructural(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetJobValue("job", `{"items":[{"price":100}],"total":1}`)
+	e := NewExecutor(s, logrus.New())
+	current := value.NewJSONObject(map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"price": 120}},
+		"total": 1,
+	})
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(current), nil)
+	job.Differ = &value.Differ{Engine: value.DiffEngineJSON}
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	res, err := e.Check(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := res.Diff()
+	if len(updates) != 1 || updates[0].Change == nil || updates[0].Change.Item["path"] != "/items/0/price" {
+		t.Errorf("updates = %v", updates)
+	}
+	if v, _ := s.GetJobValue("job"); v != `{"items":[{"price":120}],"total":1}` {
+		t.Errorf("stored value = %s", v)
+	}
+}
+
+func TestExecutor_CheckStructuralBrokenValue(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetJobValue("job", `{"items":`)
+	e := NewExecutor(s, logrus.New())
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(value.NewJSONObject(map[string]interface{}{"total": 1})), nil)
+	job.Differ = &value.Differ{Engine: value.DiffEngineJSON}
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Check(job); err == nil {
+		t.Error("expected an error for the broken previous value")
+	}
+	if v, _ := s.GetJobValue("job"); v != `{"items":` {
+		t.Errorf("stored value must be kept: %s", v)
+	}
+}
+
+func TestExecutor_CheckStructuralSwitchedBack(t *testing.T) {
+	s := store.NewMemoryStore()
+	// the value stored by the structural diff engine
+	s.SetJobValue("job", `{"items":[{"price":100}],"total":1}`)
+	e := NewExecutor(s, logrus.New())
+	current := value.NewJSONObject(map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"price": 100}},
+		"total": 1,
+	})
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(current), nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	res, err := e.Check(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updates := res.Diff(); len(updates) != 0 {
+		t.Errorf("updates = %v", updates)
+	}
+}
+
+func TestExecutor_CheckChangeRules(t *testing.T) {
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	five := 5.
+	item := func(price float64) value.Value {
+		return value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1", "price": price, "_note": "x"}})
+	}
+	// creeps by 4% against the 5% threshold
+	src := &sequenceSource{values: []value.Value{item(100), item(104), item(108), item(112)}}
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, nil)
+	job.Differ = &value.Differ{Rules: value.ChangeRules{{Key: "price", Percent: &five}}}
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		changes int
+		stored  string
+	}{
+		{0, `[{"_note":"x","id":"1","price":100}]`},
+		// suppressed and compared with 100 next time
+		{0, `[{"_note":"x","id":"1","price":100}]`},
+		{1, `[{"_note":"x","id":"1","price":108}]`},
+		{0, `[{"_note":"x","id":"1","price":108}]`},
+	}
+	for i, tt := range tests {
+		res, err := e.Check(job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes := 0
+		for _, u := range res.Diff() {
+			if u.Type == value.UpdateTypeChange {
+				changes++
+			}
+		}
+		if changes != tt.changes {
+			t.Errorf("check %d: reported updates = %v", i, res.Diff())
+		}
+		if v, _ := s.GetJobValue("job"); v != tt.stored {
+			t.Errorf("check %d: stored value = %s, want %s", i, v, tt.stored)
+		}
+	}
+}
+
+func TestExecutor_ReplaceJobs(t *testing.T) {
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	newJob := func(id string, schedule string) *Job {
+		job := NewJob(&domain.JobInfo{ID: id}, source.NewConstantSource(value.NewJSONArray(nil)), nil)
+		job.Schedule = schedule
+		return job
+	}
+	a := newJob("a", "@every 1h")
+	if err := e.ReplaceJobs([]*Job{a, newJob("b", "")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Check(a); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid jobs don't replace the current ones
+	if err := e.ReplaceJobs([]*Job{newJob("c", "invalid")}); err == nil {
+		t.Error("ReplaceJobs() should fail with the invalid schedule")
+	}
+	if err := e.ReplaceJobs([]*Job{newJob("c", ""), newJob("c", "")}); err == nil {
+		t.Error("ReplaceJobs() should fail with the duplicate ids")
+	}
+	if e.Job("a") != a || e.Job("c") != nil {
+		t.Errorf("jobs = %v", e.Jobs())
+	}
+
+	a2 := newJob("a", "@every 2h")
+	if err := e.ReplaceJobs([]*Job{a2, newJob("c", "")}); err != nil {
+		t.Fatal(err)
+	}
+	jobs := e.Jobs()
+	if len(jobs) != 2 || jobs[0] != a2 || jobs[1].ID() != "c" {
+		t.Errorf("jobs = %v", jobs)
+	}
+	if a2.lock != a.lock {
+		t.Error("replacing job should take over the run lock")
+	}
+	if v, _ := e.GetJobValue("a"); v == nil {
+		t.Error("value of the replaced job should be kept")
+	}
+	if len(e.c.Entries()) != 1 {
+		t.Errorf("schedules = %d, want 1", len(e.c.Entries()))
+	}
+}
+
+func TestExecutor_CheckTimeout(t *testing.T) {
+	e := NewExecutor(store.NewMemoryStore(), logrus.New())
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewShellSource("sleep 10"), nil)
+	job.Timeout = 50 * time.Millisecond
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := e.Check(job); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Check() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Check() should time out: elapsed=%v", elapsed)
+	}
+	if status, _ := e.GetJobStatus("job"); status == nil || status.Status != domain.StatusError {
+		t.Errorf("status = %v, want error", status)
+	}
+}
+
+// blockingAction blocks until the check is cancelled.
+type blockingAction struct{}
+
+func (a *blockingAction) Run(ctx *domain.JobContext, result *domain.Result) error {
+	<-ctx.Context().Done()
+	return ctx.Context().Err()
+}
+
+func TestExecutor_CheckActionTimeout(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetJobValue("job", `[{"id":"1"}]`)
+	e := NewExecutor(s, logrus.New())
+	current := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "2"}})
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(current), []domain.Action{new(blockingAction)})
+	job.Timeout = 10 * time.Millisecond
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Check(job); err == nil {
+		t.Error("Check() should fail with the timeout")
+	}
+	// the updates are not lost to be reported by the next check
+	if v, _ := s.GetJobValue("job"); v != `[{"id":"1"}]` {
+		t.Errorf("stored value = %s", v)
+	}
+}
@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uphy/watch-web/pkg/domain/value"
+)
+
+type (
+	// Guard is the sanity check of the fetched value.
+	// If the value violates the guard, the value is not stored and actions are not performed.
+	Guard struct {
+		MinItems          *int
+		MaxItems          *int
+		MaxRemovedPercent *float64
+		MaxAddedPercent   *float64
+		// RequiredKeys are the keys which all items should have with non-empty value.
+		RequiredKeys []string
+		// Notify sends a notification once when the guard is violated.
+		Notify bool
+	}
+)
+
+// Check returns an error if current value violates the guard.
+func (g *Guard) Check(previous, current value.ItemList, updates value.Updates) error {
+	if g == nil {
+		return nil
+	}
+	violations := make([]string, 0)
+	if g.MinItems != nil && len(current) < *g.MinItems {
+		violations = append(violations, fmt.Sprintf("too few items: min=%d, actual=%d", *g.MinItems, len(current)))
+	}
+	if g.MaxItems != nil && len(current) > *g.MaxItems {
+		violations = append(violations, fmt.Sprintf("too many items: max=%d, actual=%d", *g.MaxItems, len(current)))
+	}
+	if len(previous) > 0 {
+		var added, removed int
+		for _, u := range updates {
+			switch u.Type {
+			case value.UpdateTypeAdd:
+				added++
+			case value.UpdateTypeRemove:
+				removed++
+			}
+		}
+		if g.MaxRemovedPercent != nil {
+			if p := percent(removed, len(previous)); p > *g.MaxRemovedPercent {
+				violations = append(violations, fmt.Sprintf("too many items removed: max=%v%%, actual=%.1f%% (%d/%d)", *g.MaxRemovedPercent, p, removed, len(previous)))
+			}
+		}
+		if g.MaxAddedPercent != nil {
+			if p := percent(added, len(previous)); p > *g.MaxAddedPercent {
+				violations = append(violations, fmt.Sprintf("too many items added: max=%v%%, actual=%.1f%% (%d/%d)", *g.MaxAddedPercent, p, added, len(previous)))
+			}
+		}
+	}
+	for _, key := range g.RequiredKeys {
+		missing := 0
+		for _, item := range current {
+			if _, exist := item[key]; !exist || item.GetString(key) == "" {
+				missing++
+			}
+		}
+		if missing > 0 {
+			violations = append(violations, fmt.Sprintf("required key is missing: key=%s, items=%d/%d", key, missing, len(current)))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("guard violated: %s", strings.Join(violations, ", "))
+}
+
+func percent(n, total int) float64 {
+	return float64(n) / float64(total) * 100
+}
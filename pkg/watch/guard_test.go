@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/source"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func TestGuard_Check(t *testing.T) {
+	minItems := 2
+	maxRemoved := 50.
+	previous := value.ItemList{
+		value.Item{"id": "1", "price": "100"},
+		value.Item{"id": "2", "price": "200"},
+		value.Item{"id": "3", "price": "300"},
+	}
+	tests := []struct {
+		name    string
+		guard   *Guard
+		current value.ItemList
+		wantErr bool
+	}{
+		{
+			name:    "no guard",
+			current: value.ItemList{},
+		},
+		{
+			name:    "min items",
+			guard:   &Guard{MinItems: &minItems},
+			current: value.ItemList{value.Item{"id": "1", "price": "100"}},
+			wantErr: true,
+		},
+		{
+			name:    "max removed percent",
+			guard:   &Guard{MaxRemovedPercent: &maxRemoved},
+			current: value.ItemList{value.Item{"id": "1", "price": "100"}},
+			wantErr: true,
+		},
+		{
+			name:    "within max removed percent",
+			guard:   &Guard{MaxRemovedPercent: &maxRemoved},
+			current: value.ItemList{value.Item{"id": "1", "price": "100"}, value.Item{"id": "2", "price": "200"}},
+		},
+		{
+			name:    "required keys",
+			guard:   &Guard{RequiredKeys: []string{"price"}},
+			current: value.ItemList{value.Item{"id": "1", "price": "100"}, value.Item{"id": "2", "price": ""}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := value.CompareItemList(previous, tt.current)
+			if err := tt.guard.Check(previous, tt.current, updates); (err != nil) != tt.wantErr {
+				t.Errorf("Guard.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecutor_CheckGuardKeepsPreviousValue(t *testing.T) {
+	s := store.NewMemoryStore()
+	previous := `[{"id":"1"},{"id":"2"}]`
+	s.SetJobValue("job", previous)
+	e := NewExecutor(s, logrus.New())
+	minItems := 1
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(value.NewJSONArray(nil)), nil)
+	job.Guard = &Guard{MinItems: &minItems}
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Check(job); err == nil {
+		t.Error("guard violation should be an error")
+	}
+	v, _ := s.GetJobValue("job")
+	if v != previous {
+		t.Errorf("previous value should be kept: %s", v)
+	}
+	status, _ := s.GetJobStatus("job")
+	if status.Status != domain.StatusError {
+		t.Errorf("status should be error: %s", status.Status)
+	}
+}
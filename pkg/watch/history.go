@@ -0,0 +1,23 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain"
+)
+
+// record adds the run to the history with the result in the status.
+// The check doesn't fail even if the history can't be stored.
+func (e *Executor) record(job *Job, run *domain.JobRun, status *domain.JobStatus) {
+	run.Duration = time.Since(run.Start).Seconds()
+	run.Status = status.Status
+	run.Error = status.Error
+	if err := e.store.AddJobRun(job.ID(), run, e.History); err != nil {
+		job.ctx.Log.WithField("err", err).Warn("Failed to store job history.")
+	}
+}
+
+// GetJobRuns returns the run history of the job from the newest.  limit <= 0 means all.
+func (e *Executor) GetJobRuns(jobID string, limit int) ([]domain.JobRun, error) {
+	return e.store.GetJobRuns(jobID, limit)
+}
@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func TestExecutor_CheckHistory(t *testing.T) {
+	e := NewExecutor(store.NewMemoryStore(), logrus.New())
+	e.History.MaxRuns = 2
+	one := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}})
+	two := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}})
+	src := &sequenceSource{values: []value.Value{one, two, two}}
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, nil)
+	bad := NewJob(&domain.JobInfo{ID: "bad"}, &failingSource{fail: true}, nil)
+	for _, j := range []*Job{job, bad} {
+		if err := e.AddJob(j, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.Check(job)
+	e.Check(job)
+	e.Check(job)
+	e.Check(bad)
+
+	runs, err := e.GetJobRuns("job", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first run is removed by the retention
+	if len(runs) != 2 {
+		t.Fatalf("unexpected number of runs: %d", len(runs))
+	}
+	if runs[0].Status != domain.StatusOK || runs[0].Items != 2 || len(runs[0].Updates) != 0 {
+		t.Errorf("unexpected newest run: %#v", runs[0])
+	}
+	if runs[1].Status != domain.StatusOK || runs[1].Items != 2 || len(runs[1].Updates) != 1 || runs[1].Updates[0].Type != value.UpdateTypeAdd {
+		t.Errorf("unexpected second run: %#v", runs[1])
+	}
+	if runs[0].Start.Before(runs[1].Start) {
+		t.Errorf("runs must be sorted from the newest: %v, %v", runs[0].Start, runs[1].Start)
+	}
+
+	runs, err = e.GetJobRuns("bad", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Status != domain.StatusError || runs[0].Error == nil {
+		t.Errorf("unexpected failed run: %#v", runs)
+	}
+}
+
+func TestHistoryRetention_Apply(t *testing.T) {
+	now := time.Now()
+	runs := []domain.JobRun{
+		{Start: now.Add(-time.Minute)},
+		{Start: now.Add(-time.Hour)},
+		{Start: now.Add(-2 * time.Hour)},
+	}
+	tests := []struct {
+		name      string
+		retention domain.HistoryRetention
+		kept      int
+	}{
+		{"default", domain.HistoryRetention{}, 3},
+		{"max runs", domain.HistoryRetention{MaxRuns: 2}, 2},
+		{"max age", domain.HistoryRetention{MaxAge: 90 * time.Minute}, 2},
+		{"max runs and age", domain.HistoryRetention{MaxRuns: 1, MaxAge: 90 * time.Minute}, 1},
+		{"all expired", domain.HistoryRetention{MaxAge: time.Second}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kept := tt.retention.Apply(runs, now); len(kept) != tt.kept {
+				t.Errorf("expected %d runs but %d", tt.kept, len(kept))
+			}
+		})
+	}
+}
@@ -2,9 +2,13 @@ package watch
 
 import (
 	"fmt"
+	"net/url"
+	"time"
 
+	"github.com/robfig/cron"
 	"github.com/sirupsen/logrus"
 	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
 )
 
 type (
@@ -13,17 +17,60 @@ type (
 		source  domain.Source
 		ctx     *domain.JobContext
 		actions []domain.Action
+		// Guard is the sanity check of the fetched value.  nil means no check.
+		Guard *Guard
+		// Differ is the job specific diff settings.  nil means the default.
+		Differ *value.Differ
+		// ConfirmAfter is the number of consecutive checks an update must be observed before reported.
+		ConfirmAfter int
+		// RunPolicy is how to handle the run requested while the job is running.
+		RunPolicy RunPolicy
+		// Schedule is the cron spec of the job.  Empty means the job runs only on demand.
+		Schedule string
+		// ScheduleOptions are the time zone, the windows and the jitter of the Schedule.
+		ScheduleOptions ScheduleOptions
+		// Adaptive schedules the job by the change frequency instead of the Schedule.
+		Adaptive *AdaptiveSchedule
+		// AlertAfter is the number of the consecutive failures to notify the failure.  0 means no notification.
+		AlertAfter int
+		// Timeout is the maximum duration of a check including the actions.  0 means no timeout.
+		Timeout time.Duration
+
+		// lock is shared with the job replacing this one by reloading.
+		lock *runLock
+		// schedule is the schedule created from the Schedule or the Adaptive.
+		schedule cron.Schedule
 	}
 )
 
 func NewJob(info *domain.JobInfo, source domain.Source, actions []domain.Action) *Job {
-	return &Job{info, source, nil, actions}
+	return &Job{Info: info, source: source, actions: actions, lock: new(runLock)}
 }
 
 func (j *Job) ID() string {
 	return j.Info.ID
 }
 
+// Host returns the host the job sends requests to.
+// The host of the source is preferred and the host of the link is used otherwise.
+func (j *Job) Host() string {
+	if h, ok := j.source.(domain.HostSource); ok {
+		if host := h.Host(); host != "" {
+			return host
+		}
+	}
+	u, err := url.Parse(j.Info.Link)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// scheduled returns true if the job runs periodically.
+func (j *Job) scheduled() bool {
+	return j.Schedule != "" || j.Adaptive != nil
+}
+
 func (j *Job) failed(status *domain.JobStatus, msg string, err error) {
 	errw := fmt.Errorf("%s: %w", msg, err)
 	errorString := errw.Error()
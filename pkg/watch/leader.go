@@ -0,0 +1,117 @@
+package watch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+)
+
+const (
+	// DefaultLeaderTTL is the default expiration of the leader lease.
+	// Another instance takes over the leader within this duration after the leader dies.
+	DefaultLeaderTTL = 30 * time.Second
+
+	leaderLeaseKey = "leader"
+)
+
+// ErrNotLeader is returned if the scheduled check is interrupted because this instance lost the leadership.
+var ErrNotLeader = errors.New("not the leader")
+
+type (
+	// LeaderElector elects the only instance running the schedule among the instances sharing the store.
+	// The leader renews the lease periodically and the others try to acquire it.
+	LeaderElector struct {
+		leaser domain.Leaser
+		// ID identifies this instance as the holder of the lease.
+		ID string
+		// TTL is the expiration of the lease.
+		TTL time.Duration
+		log *logrus.Logger
+
+		mu sync.Mutex
+		// until is the time this instance is the leader until.
+		// It is measured from the time before acquiring the lease so that it expires before the lease in the store.
+		until time.Time
+	}
+)
+
+// NewLeaderElector creates a LeaderElector with the unique ID of this instance.
+func NewLeaderElector(leaser domain.Leaser, log *logrus.Logger) *LeaderElector {
+	b := make([]byte, 8)
+	rand.Read(b)
+	hostname, _ := os.Hostname()
+	return &LeaderElector{
+		leaser: leaser,
+		ID:     hostname + "-" + hex.EncodeToString(b),
+		TTL:    DefaultLeaderTTL,
+		log:    log,
+	}
+}
+
+// IsLeader returns true if this instance is the leader.
+func (l *LeaderElector) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Now().Before(l.until)
+}
+
+// Run tries to acquire or renew the lease every third of the TTL until stop is closed.
+// elected is called when this instance becomes the leader.
+// The lease is released on stop so that another instance takes over immediately.
+func (l *LeaderElector) Run(stop <-chan struct{}, elected func()) {
+	ticker := time.NewTicker(l.TTL / 3)
+	defer ticker.Stop()
+	for {
+		wasLeader := l.IsLeader()
+		if l.elect() && !wasLeader {
+			l.log.WithField("id", l.ID).Info("Elected as the leader.")
+			go elected()
+		} else if wasLeader && !l.IsLeader() {
+			l.log.WithField("id", l.ID).Warn("Lost the leadership.")
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			l.resign()
+			return
+		}
+	}
+}
+
+// elect tries to acquire or renew the lease and returns true if this instance is the leader.
+func (l *LeaderElector) elect() bool {
+	start := time.Now()
+	acquired, err := l.leaser.AcquireLease(leaderLeaseKey, l.ID, l.TTL)
+	if err != nil {
+		// keep the leadership until the current lease expires because the lease in the store may still be valid.
+		l.log.WithField("err", err).Warn("Failed to acquire leader lease.")
+		return l.IsLeader()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if acquired {
+		l.until = start.Add(l.TTL)
+	} else {
+		l.until = time.Time{}
+	}
+	return acquired
+}
+
+func (l *LeaderElector) resign() {
+	l.mu.Lock()
+	wasLeader := time.Now().Before(l.until)
+	l.until = time.Time{}
+	l.mu.Unlock()
+	if !wasLeader {
+		return
+	}
+	if err := l.leaser.ReleaseLease(leaderLeaseKey, l.ID); err != nil {
+		l.log.WithField("err", err).Warn("Failed to release leader lease.")
+	}
+}
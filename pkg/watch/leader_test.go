@@ -0,0 +1,221 @@
+package watch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func newTestLeaderElector(s *store.MemoryStore) *LeaderElector {
+	l := NewLeaderElector(s, logrus.New())
+	l.TTL = 90 * time.Millisecond
+	return l
+}
+
+func TestLeaderElector_Elect(t *testing.T) {
+	s := store.NewMemoryStore()
+	l1 := newTestLeaderElector(s)
+	l2 := newTestLeaderElector(s)
+	if l1.ID == l2.ID {
+		t.Fatalf("IDs must be unique: %s", l1.ID)
+	}
+	if !l1.elect() {
+		t.Fatal("l1 must be elected")
+	}
+	if l2.elect() {
+		t.Fatal("l2 must not be elected while l1 holds the lease")
+	}
+	// renew
+	if !l1.elect() {
+		t.Fatal("l1 must renew the lease")
+	}
+	if !l1.IsLeader() || l2.IsLeader() {
+		t.Fatalf("unexpected leaders: l1=%v, l2=%v", l1.IsLeader(), l2.IsLeader())
+	}
+
+	// l1 dies without releasing the lease
+	time.Sleep(l1.TTL)
+	if l1.IsLeader() {
+		t.Error("l1 must not be the leader after the lease expired")
+	}
+	if !l2.elect() {
+		t.Fatal("l2 must take over the expired lease")
+	}
+	if l1.elect() {
+		t.Fatal("l1 must not be elected while l2 holds the lease")
+	}
+}
+
+func TestLeaderElector_Run(t *testing.T) {
+	s := store.NewMemoryStore()
+	l1 := newTestLeaderElector(s)
+	l2 := newTestLeaderElector(s)
+	elected1 := make(chan struct{}, 1)
+	elected2 := make(chan struct{}, 1)
+	stop1 := make(chan struct{})
+	stop2 := make(chan struct{})
+	done1 := make(chan struct{})
+	defer close(stop2)
+
+	go func() {
+		l1.Run(stop1, func() { elected1 <- struct{}{} })
+		close(done1)
+	}()
+	select {
+	case <-elected1:
+	case <-time.After(time.Second):
+		t.Fatal("l1 must be elected")
+	}
+	go l2.Run(stop2, func() { elected2 <- struct{}{} })
+
+	// l1 keeps the leadership by renewing the lease
+	select {
+	case <-elected2:
+		t.Fatal("l2 must not be elected while l1 is running")
+	case <-time.After(2 * l1.TTL):
+	}
+	if !l1.IsLeader() {
+		t.Fatal("l1 must still be the leader")
+	}
+
+	// l1 resigns on stop and l2 takes over
+	close(stop1)
+	<-done1
+	if l1.IsLeader() {
+		t.Error("l1 must not be the leader after stopped")
+	}
+	select {
+	case <-elected2:
+	case <-time.After(time.Second):
+		t.Fatal("l2 must take over the leader")
+	}
+	if !l2.IsLeader() {
+		t.Error("l2 must be the leader")
+	}
+}
+
+func TestExecutor_RunLeader(t *testing.T) {
+	s := store.NewMemoryStore()
+	other := newTestLeaderElector(s)
+	other.TTL = time.Hour
+	if !other.elect() {
+		t.Fatal("other must be elected")
+	}
+
+	e := NewExecutor(s, logrus.New())
+	e.InitialRun = true
+	e.Leader = newTestLeaderElector(s)
+	src := &blockingSource{started: make(chan struct{}, 1), release: make(chan struct{})}
+	close(src.release)
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	go e.Run()
+	defer e.Stop()
+
+	// the initial run is done only by the leader
+	select {
+	case <-src.started:
+		t.Fatal("the job must not be checked while another instance is the leader")
+	case <-time.After(2 * e.Leader.TTL):
+	}
+	other.resign()
+	select {
+	case <-src.started:
+	case <-time.After(time.Second):
+		t.Fatal("the job must be checked after elected")
+	}
+}
+
+// actionRecorder counts the runs of the action.
+type actionRecorder struct {
+	runs int
+}
+
+func (r *actionRecorder) Run(ctx *domain.JobContext, result *domain.Result) error {
+	r.runs++
+	return nil
+}
+
+// resigningSource makes the leader lose the leadership while fetching.
+type resigningSource struct {
+	leader *LeaderElector
+}
+
+func (s *resigningSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
+	s.leader.resign()
+	return value.NewJSONArray([]interface{}{map[string]interface{}{"id": "2"}}), nil
+}
+
+func TestExecutor_CheckLostLeadership(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetJobValue("job", `[{"id":"1"}]`)
+	s.SetJobStatus("job", &domain.JobStatus{Status: domain.StatusOK, Count: 5})
+	e := NewExecutor(s, logrus.New())
+	e.Leader = newTestLeaderElector(s)
+	e.Leader.TTL = time.Hour
+	recorder := new(actionRecorder)
+	job := NewJob(&domain.JobInfo{ID: "job"}, &resigningSource{e.Leader}, []domain.Action{recorder})
+	job.ConfirmAfter = 2
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	// not checked at all while not the leader
+	if _, err := e.checkJob(job, true); err != ErrNotLeader {
+		t.Errorf("checkJob() error = %v, want %v", err, ErrNotLeader)
+	}
+	if !e.Leader.elect() {
+		t.Fatal("must be elected")
+	}
+	if _, err := e.checkJob(job, true); err != ErrNotLeader {
+		t.Errorf("checkJob() error = %v, want %v", err, ErrNotLeader)
+	}
+	if recorder.runs != 0 {
+		t.Errorf("actions must not run after the leadership is lost: %d", recorder.runs)
+	}
+	if v, _ := s.GetJobValue("job"); v != `[{"id":"1"}]` {
+		t.Errorf("stored value = %s", v)
+	}
+	// the status and the history are left to the new leader
+	if status, _ := s.GetJobStatus("job"); status.Status != domain.StatusOK || status.Count != 5 || len(status.Pending) != 0 {
+		t.Errorf("status must not be changed: %#v", status)
+	}
+	if runs, _ := s.GetJobRuns("job", 0); len(runs) != 0 {
+		t.Errorf("runs must not be recorded: %v", runs)
+	}
+	var buf bytes.Buffer
+	e.WriteMetrics(&buf)
+	if strings.Contains(buf.String(), `watch_checks_total{job="job"}`) {
+		t.Errorf("skipped check must not be counted:\n%s", buf.String())
+	}
+
+	// the checks requested to this instance are not fenced
+	if _, err := e.Check(job); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if recorder.runs != 1 {
+		t.Errorf("actions = %d, want 1", recorder.runs)
+	}
+}
+
+func TestExecutor_Elected(t *testing.T) {
+	e := NewExecutor(store.NewMemoryStore(), logrus.New())
+	e.InitialRun = true
+	src := &sequenceSource{values: []value.Value{value.NewJSONArray(nil), value.NewJSONArray(nil)}}
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	e.elected()
+	e.elected()
+	if src.index != 1 {
+		t.Errorf("the initial run must be done only on the first election: checked %d times", src.index)
+	}
+}
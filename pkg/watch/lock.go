@@ -0,0 +1,126 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain"
+)
+
+const (
+	// RunPolicySkip doesn't run the job if it is already running.
+	RunPolicySkip RunPolicy = "skip"
+	// RunPolicyQueue runs the job again after the running one.  At most one run is queued.
+	RunPolicyQueue RunPolicy = "queue"
+	// RunPolicyWait waits for the running one and returns its result.
+	RunPolicyWait RunPolicy = "wait"
+
+	// DefaultLockTTL is the default expiration of the lock in the store.
+	DefaultLockTTL = 10 * time.Minute
+
+	lockKeyPrefix = "lock:job:"
+)
+
+// ErrAlreadyRunning is returned if the job is already running.
+var ErrAlreadyRunning = errors.New("already running")
+
+type (
+	// RunPolicy is how to handle the run requested while the same job is running.
+	RunPolicy string
+	// runLock prevents the overlapping runs of a job in the process.
+	runLock struct {
+		mu      sync.Mutex
+		running *jobRun
+		queued  bool
+	}
+	jobRun struct {
+		done chan struct{}
+		res  *domain.Result
+		err  error
+	}
+)
+
+func ParseRunPolicy(s string) (RunPolicy, error) {
+	switch p := RunPolicy(s); p {
+	case "":
+		return RunPolicySkip, nil
+	case RunPolicySkip, RunPolicyQueue, RunPolicyWait:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported run policy: %s", s)
+	}
+}
+
+// start starts a run of the job according to the policy.
+// If the run can't be started, returns the result of the run to be returned instead.
+func (l *runLock) start(policy RunPolicy) (run *jobRun, res *domain.Result, err error) {
+	queued := false
+	l.mu.Lock()
+	for l.running != nil {
+		current := l.running
+		switch policy {
+		case RunPolicyWait:
+			l.mu.Unlock()
+			<-current.done
+			return nil, current.res, current.err
+		case RunPolicyQueue:
+			if l.queued && !queued {
+				l.mu.Unlock()
+				return nil, nil, ErrAlreadyRunning
+			}
+			l.queued = true
+			queued = true
+			l.mu.Unlock()
+			<-current.done
+			l.mu.Lock()
+		default:
+			l.mu.Unlock()
+			return nil, nil, ErrAlreadyRunning
+		}
+	}
+	if queued {
+		l.queued = false
+	}
+	run = &jobRun{done: make(chan struct{})}
+	l.running = run
+	l.mu.Unlock()
+	return run, nil, nil
+}
+
+// finish finishes the run and passes the result to the waiting ones.
+func (l *runLock) finish(run *jobRun, res *domain.Result, err error) {
+	run.res = res
+	run.err = err
+	l.mu.Lock()
+	l.running = nil
+	l.mu.Unlock()
+	close(run.done)
+}
+
+// lockStore acquires the lock of the job in the store shared by the instances.
+// Returns the no-op function if the store doesn't support the lock or the lock is disabled.
+func (e *Executor) lockStore(job *Job) (unlock func(), err error) {
+	locker, ok := e.store.(domain.Locker)
+	if !e.DistributedLock || !ok {
+		return func() {}, nil
+	}
+	ttl := e.LockTTL
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+	key := lockKeyPrefix + job.ID()
+	token, locked, err := locker.TryLock(key, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if !locked {
+		return nil, ErrAlreadyRunning
+	}
+	return func() {
+		if err := locker.Unlock(key, token); err != nil {
+			job.ctx.Log.WithField("err", err).Warn("Failed to unlock job.")
+		}
+	}, nil
+}
@@ -0,0 +1,158 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/source"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+// blockingSource blocks the fetch until released.
+type blockingSource struct {
+	fetched int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
+	atomic.AddInt32(&s.fetched, 1)
+	s.started <- struct{}{}
+	<-s.release
+	return value.NewJSONArray(nil), nil
+}
+
+func TestExecutor_CheckRunPolicy(t *testing.T) {
+	tests := []struct {
+		policy  RunPolicy
+		fetched int32
+		errs    int
+	}{
+		{RunPolicySkip, 1, 2},
+		{RunPolicyQueue, 2, 1},
+		{RunPolicyWait, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			e := NewExecutor(store.NewMemoryStore(), logrus.New())
+			e.Pool = NewWorkerPool(3)
+			src := &blockingSource{started: make(chan struct{}, 3), release: make(chan struct{})}
+			job := NewJob(&domain.JobInfo{ID: "job"}, src, nil)
+			job.RunPolicy = tt.policy
+			if err := e.AddJob(job, nil); err != nil {
+				t.Fatal(err)
+			}
+			var errs int32
+			wg := new(sync.WaitGroup)
+			check := func() {
+				defer wg.Done()
+				if _, err := e.Check(job); err == ErrAlreadyRunning {
+					atomic.AddInt32(&errs, 1)
+				}
+			}
+			wg.Add(1)
+			go check()
+			<-src.started
+			wg.Add(2)
+			go check()
+			go check()
+			// wait for the overlapping runs to be handled
+			time.Sleep(50 * time.Millisecond)
+			close(src.release)
+			wg.Wait()
+			if src.fetched != tt.fetched {
+				t.Errorf("fetched = %d, want %d", src.fetched, tt.fetched)
+			}
+			if int(errs) != tt.errs {
+				t.Errorf("already running errors = %d, want %d", errs, tt.errs)
+			}
+		})
+	}
+}
+
+func TestExecutor_CheckDistributedLock(t *testing.T) {
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	e.DistributedLock = true
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(value.NewJSONArray(nil)), nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	// another instance holds the lock
+	token, _, _ := s.TryLock(lockKeyPrefix+"job", time.Minute)
+	if _, err := e.Check(job); err != ErrAlreadyRunning {
+		t.Errorf("Check() error = %v, want %v", err, ErrAlreadyRunning)
+	}
+	s.Unlock(lockKeyPrefix+"job", token)
+	if _, err := e.Check(job); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if _, locked, _ := s.TryLock(lockKeyPrefix+"job", time.Minute); !locked {
+		t.Error("lock should be released after the check")
+	}
+}
+
+func TestExecutor_CheckDistributedLockTakenOver(t *testing.T) {
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	e.DistributedLock = true
+	e.LockTTL = 10 * time.Millisecond
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(value.NewJSONArray(nil)), nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := e.lockStore(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the lease expires and another instance takes over the lock
+	time.Sleep(20 * time.Millisecond)
+	token, locked, _ := s.TryLock(lockKeyPrefix+"job", time.Minute)
+	if !locked {
+		t.Fatal("lock should be taken over after the expiration")
+	}
+	unlock()
+	if _, locked, _ := s.TryLock(lockKeyPrefix+"job", time.Minute); locked {
+		t.Error("lock taken over by another should not be released")
+	}
+	s.Unlock(lockKeyPrefix+"job", token)
+	if _, locked, _ := s.TryLock(lockKeyPrefix+"job", time.Minute); !locked {
+		t.Error("lock should be released by the owner")
+	}
+}
+
+func TestExecutor_CheckDistributedLockAfterPool(t *testing.T) {
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	e.DistributedLock = true
+	e.Pool = NewWorkerPool(1)
+	job := NewJob(&domain.JobInfo{ID: "job"}, source.NewConstantSource(value.NewJSONArray(nil)), nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	release, _ := e.Pool.Acquire(context.Background(), "")
+	done := make(chan error)
+	go func() {
+		_, err := e.Check(job)
+		done <- err
+	}()
+	for e.Pool.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// the lock must not be taken while waiting for the pool
+	token, locked, _ := s.TryLock(lockKeyPrefix+"job", time.Minute)
+	if !locked {
+		t.Error("lock should not be held while waiting for the pool")
+	}
+	s.Unlock(lockKeyPrefix+"job", token)
+	release()
+	if err := <-done; err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+}
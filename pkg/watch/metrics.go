@@ -0,0 +1,251 @@
+package watch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+)
+
+const (
+	metricTypeCounter   = "counter"
+	metricTypeGauge     = "gauge"
+	metricTypeHistogram = "histogram"
+)
+
+// durationBuckets are the upper bounds in seconds of the histograms of the durations.
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type (
+	// metrics collects the metrics of the checks in the process.
+	// It is written in the Prometheus text exposition format.
+	metrics struct {
+		checks            *metricVec
+		failures          *metricVec
+		checkDuration     *metricVec
+		fetchDuration     *metricVec
+		transformDuration *metricVec
+		items             *metricVec
+		updates           *metricVec
+		actionSends       *metricVec
+		actionErrors      *metricVec
+		queueDepth        *metricVec
+		runningChecks     *metricVec
+	}
+	// metricVec is a metric family partitioned by the label values.
+	metricVec struct {
+		name    string
+		help    string
+		typ     string
+		labels  []string
+		buckets []float64
+
+		mu     sync.Mutex
+		series map[string]*metricSeries
+	}
+	metricSeries struct {
+		labelValues []string
+		// value is the value of the counter or the gauge.
+		value float64
+		// counts are the number of the observations in each bucket of the histogram, not cumulative.
+		counts []uint64
+		sum    float64
+		count  uint64
+	}
+)
+
+func newMetrics() *metrics {
+	return &metrics{
+		checks:            newMetricVec("watch_checks_total", "The number of the checks.", metricTypeCounter, "job"),
+		failures:          newMetricVec("watch_check_failures_total", "The number of the failed checks.", metricTypeCounter, "job"),
+		checkDuration:     newHistogramVec("watch_check_duration_seconds", "The duration of the checks including the actions.", durationBuckets, "job"),
+		fetchDuration:     newHistogramVec("watch_fetch_duration_seconds", "The duration of fetching the value excluding the transforms.", durationBuckets, "job"),
+		transformDuration: newHistogramVec("watch_transform_duration_seconds", "The duration of transforming the fetched value.", durationBuckets, "job"),
+		items:             newMetricVec("watch_items", "The number of the items fetched by the last check.", metricTypeGauge, "job"),
+		updates:           newMetricVec("watch_updates_total", "The number of the detected updates.", metricTypeCounter, "job", "type"),
+		actionSends:       newMetricVec("watch_action_sends_total", "The number of the actions and the notifications sent.", metricTypeCounter, "job"),
+		actionErrors:      newMetricVec("watch_action_errors_total", "The number of the actions and the notifications failed.", metricTypeCounter, "job"),
+		queueDepth:        newMetricVec("watch_queue_depth", "The number of the checks waiting for the workers.", metricTypeGauge),
+		runningChecks:     newMetricVec("watch_running_checks", "The number of the checks running on the workers.", metricTypeGauge),
+	}
+}
+
+func newMetricVec(name, help, typ string, labels ...string) *metricVec {
+	return &metricVec{name: name, help: help, typ: typ, labels: labels, series: make(map[string]*metricSeries)}
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *metricVec {
+	v := newMetricVec(name, help, metricTypeHistogram, labels...)
+	v.buckets = buckets
+	return v
+}
+
+// checked records the result and the duration of the check.
+func (m *metrics) checked(jobID string, status *domain.JobStatus, duration time.Duration) {
+	m.checks.add(1, jobID)
+	m.checkDuration.observe(duration.Seconds(), jobID)
+	if status.Status == domain.StatusError {
+		m.failures.add(1, jobID)
+	}
+}
+
+// fetched records the durations and the number of the items of the fetch.
+// total includes the transforms.
+func (m *metrics) fetched(jobID string, total time.Duration, timings *domain.Timings, items int) {
+	transform := timings.Transform()
+	m.fetchDuration.observe((total - transform).Seconds(), jobID)
+	m.transformDuration.observe(transform.Seconds(), jobID)
+	m.items.set(float64(items), jobID)
+}
+
+func (m *metrics) updated(jobID string, updates value.Updates) {
+	for _, u := range updates {
+		m.updates.add(1, jobID, string(u.Type))
+	}
+}
+
+func (m *metrics) sent(jobID string, err error) {
+	m.actionSends.add(1, jobID)
+	if err != nil {
+		m.actionErrors.add(1, jobID)
+	}
+}
+
+// remove deletes the series of the jobs.
+func (m *metrics) remove(jobIDs ...string) {
+	for _, v := range m.vecs() {
+		v.remove(jobIDs...)
+	}
+}
+
+// write writes the metrics in the Prometheus text exposition format.
+func (m *metrics) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, v := range m.vecs() {
+		v.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (m *metrics) vecs() []*metricVec {
+	return []*metricVec{
+		m.checks, m.failures, m.checkDuration, m.fetchDuration, m.transformDuration, m.items,
+		m.updates, m.actionSends, m.actionErrors, m.queueDepth, m.runningChecks,
+	}
+}
+
+func (v *metricVec) get(labelValues []string) *metricSeries {
+	key := strings.Join(labelValues, "\x00")
+	s, exist := v.series[key]
+	if !exist {
+		s = &metricSeries{labelValues: labelValues}
+		if v.typ == metricTypeHistogram {
+			s.counts = make([]uint64, len(v.buckets))
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+func (v *metricVec) add(delta float64, labelValues ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.get(labelValues).value += delta
+}
+
+func (v *metricVec) set(value float64, labelValues ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.get(labelValues).value = value
+}
+
+func (v *metricVec) observe(value float64, labelValues ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s := v.get(labelValues)
+	for i, le := range v.buckets {
+		if value <= le {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// remove deletes the series whose job label is any of the jobs.
+func (v *metricVec) remove(jobIDs ...string) {
+	if len(v.labels) == 0 || v.labels[0] != "job" {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	removed := make(map[string]bool, len(jobIDs))
+	for _, id := range jobIDs {
+		removed[id] = true
+	}
+	for key, s := range v.series {
+		if removed[s.labelValues[0]] {
+			delete(v.series, key)
+		}
+	}
+}
+
+func (v *metricVec) write(w *bufio.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.typ)
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := v.series[key]
+		if v.typ != metricTypeHistogram {
+			fmt.Fprintf(w, "%s%s %s\n", v.name, v.formatLabels(s.labelValues, ""), formatFloat(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, le := range v.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.formatLabels(s.labelValues, formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.formatLabels(s.labelValues, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.name, v.formatLabels(s.labelValues, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.name, v.formatLabels(s.labelValues, ""), s.count)
+	}
+}
+
+// formatLabels formats the labels with the le label of the histogram bucket if le is not empty.
+func (v *metricVec) formatLabels(labelValues []string, le string) string {
+	pairs := make([]string, 0, len(labelValues)+1)
+	for i, name := range v.labels {
+		pairs = append(pairs, name+`="`+escapeLabelValue(labelValues[i])+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
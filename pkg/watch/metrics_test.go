@@ -0,0 +1,96 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/source"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func TestMetricVec_Write(t *testing.T) {
+	v := newHistogramVec("test_seconds", "Test.", []float64{0.5, 1}, "job")
+	v.observe(0.2, `a"b`)
+	v.observe(0.7, `a"b`)
+	v.observe(3, `a"b`)
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	v.write(w)
+	w.Flush()
+	expected := `# HELP test_seconds Test.
+# TYPE test_seconds histogram
+test_seconds_bucket{job="a\"b",le="0.5"} 1
+test_seconds_bucket{job="a\"b",le="1"} 2
+test_seconds_bucket{job="a\"b",le="+Inf"} 3
+test_seconds_sum{job="a\"b"} 3.9
+test_seconds_count{job="a\"b"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestExecutor_WriteMetrics(t *testing.T) {
+	e := NewExecutor(store.NewMemoryStore(), logrus.New())
+	one := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}})
+	two := value.NewJSONArray([]interface{}{map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}})
+	src := source.NewTransformerSource(&sequenceSource{values: []value.Value{one, two}}, nil)
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, []domain.Action{new(notificationRecorder)})
+	bad := NewJob(&domain.JobInfo{ID: "bad"}, &failingSource{fail: true}, nil)
+	for _, j := range []*Job{job, bad} {
+		if err := e.AddJob(j, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.Check(job)
+	e.Check(job)
+	e.Check(bad)
+
+	var buf bytes.Buffer
+	if err := e.WriteMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`watch_checks_total{job="bad"} 1`,
+		`watch_checks_total{job="job"} 2`,
+		`watch_check_failures_total{job="bad"} 1`,
+		`watch_check_duration_seconds_count{job="job"} 2`,
+		`watch_check_duration_seconds_count{job="bad"} 1`,
+		`watch_fetch_duration_seconds_count{job="job"} 2`,
+		`watch_transform_duration_seconds_count{job="job"} 2`,
+		`watch_items{job="job"} 2`,
+		`watch_updates_total{job="job",type="add"} 1`,
+		`watch_action_sends_total{job="job"} 1`,
+		`watch_queue_depth 0`,
+		`watch_running_checks 0`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("metric not found: %s\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, `watch_check_failures_total{job="job"}`) {
+		t.Errorf("succeeded job must not have failures:\n%s", out)
+	}
+
+	// the series of the removed jobs are deleted
+	if err := e.ReplaceJobs([]*Job{job}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := e.WriteMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out = buf.String()
+	if strings.Contains(out, `job="bad"`) {
+		t.Errorf("series of the removed job must be deleted:\n%s", out)
+	}
+	if !strings.Contains(out, `watch_checks_total{job="job"} 2`+"\n") {
+		t.Errorf("series of the kept job must not be deleted:\n%s", out)
+	}
+}
@@ -0,0 +1,195 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWorkers is the default number of the jobs checked concurrently.
+	DefaultWorkers = 1
+)
+
+// ErrPoolClosed is returned by Acquire after the pool is closed.
+var ErrPoolClosed = errors.New("worker pool closed")
+
+type (
+	// WorkerPool limits the number of the jobs checked concurrently.
+	// In addition to the global limit, the jobs of the same host are limited by HostLimit
+	// not to send too many requests to a single host.
+	WorkerPool struct {
+		workers chan struct{}
+		// PerHost is the default limit for each host.
+		PerHost HostLimit
+		// Hosts are the limits for the specific hosts.
+		Hosts map[string]HostLimit
+
+		mu    sync.Mutex
+		hosts map[string]*hostLimiter
+		// waiting is the number of the jobs waiting for the workers or the hosts.
+		waiting int
+		// closed is closed by Close to give up the waiting jobs.
+		closed    chan struct{}
+		closeOnce sync.Once
+	}
+	// HostLimit is the limit of the jobs for a host.
+	HostLimit struct {
+		// Concurrency is the maximum number of the jobs running concurrently.  0 means unlimited.
+		Concurrency int
+		// MinDelay is the minimum interval between the starts of the jobs.
+		MinDelay time.Duration
+	}
+	hostLimiter struct {
+		slots chan struct{}
+		delay time.Duration
+
+		mu sync.Mutex
+		// next is the earliest time the next job can start.
+		next time.Time
+	}
+)
+
+// NewWorkerPool creates a WorkerPool which runs the given number of the jobs concurrently.
+func NewWorkerPool(workers int) *WorkerPool {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	return &WorkerPool{
+		workers: make(chan struct{}, workers),
+		Hosts:   make(map[string]HostLimit),
+		hosts:   make(map[string]*hostLimiter),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Close makes the waiting and the later Acquire fail with ErrPoolClosed.  The running jobs are not affected.
+func (p *WorkerPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+	})
+}
+
+// Acquire blocks until a job of the host can run and returns the function to release it.
+// Jobs without host are limited only by the number of the workers.
+// Returns the error of ctx if ctx is done, or ErrPoolClosed if the pool is closed, while waiting.
+func (p *WorkerPool) Acquire(ctx context.Context, host string) (release func(), err error) {
+	p.mu.Lock()
+	p.waiting++
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.waiting--
+		p.mu.Unlock()
+	}()
+	select {
+	case <-p.closed:
+		return nil, ErrPoolClosed
+	default:
+	}
+	h := p.hostLimiter(host)
+	// acquire the host first not to occupy the worker while waiting for the host.
+	if h != nil {
+		if err := h.acquire(ctx, p.closed); err != nil {
+			return nil, err
+		}
+	}
+	select {
+	case p.workers <- struct{}{}:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-p.closed:
+		err = ErrPoolClosed
+	}
+	if err != nil {
+		if h != nil {
+			h.release()
+		}
+		return nil, err
+	}
+	return func() {
+		<-p.workers
+		if h != nil {
+			h.release()
+		}
+	}, nil
+}
+
+// Waiting returns the number of the jobs waiting to run.
+func (p *WorkerPool) Waiting() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.waiting
+}
+
+// Running returns the number of the jobs running on the workers.
+func (p *WorkerPool) Running() int {
+	return len(p.workers)
+}
+
+func (p *WorkerPool) hostLimiter(host string) *hostLimiter {
+	if host == "" {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if h, exist := p.hosts[host]; exist {
+		return h
+	}
+	limit, exist := p.Hosts[host]
+	if !exist {
+		limit = p.PerHost
+	}
+	var h *hostLimiter
+	if limit.Concurrency > 0 || limit.MinDelay > 0 {
+		h = &hostLimiter{delay: limit.MinDelay}
+		if limit.Concurrency > 0 {
+			h.slots = make(chan struct{}, limit.Concurrency)
+		}
+	}
+	p.hosts[host] = h
+	return h
+}
+
+func (h *hostLimiter) acquire(ctx context.Context, closed <-chan struct{}) error {
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-closed:
+			return ErrPoolClosed
+		}
+	}
+	if h.delay <= 0 {
+		return nil
+	}
+	// reserve the start time so that the concurrent jobs keep the interval too.
+	h.mu.Lock()
+	now := time.Now()
+	start := h.next
+	if start.Before(now) {
+		start = now
+	}
+	h.next = start.Add(h.delay)
+	h.mu.Unlock()
+	timer := time.NewTimer(start.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		h.release()
+		return ctx.Err()
+	case <-closed:
+		h.release()
+		return ErrPoolClosed
+	}
+}
+
+func (h *hostLimiter) release() {
+	if h.slots != nil {
+		<-h.slots
+	}
+}
@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runConcurrently runs the jobs of the hosts with the pool and returns the max number of the jobs running concurrently.
+func runConcurrently(p *WorkerPool, hosts []string) (total int32, perHost map[string]int32) {
+	var running, max int32
+	hostRunning := make(map[string]*int32)
+	perHost = make(map[string]int32)
+	var mu sync.Mutex
+	for _, h := range hosts {
+		hostRunning[h] = new(int32)
+	}
+	wg := new(sync.WaitGroup)
+	for _, h := range hosts {
+		wg.Add(1)
+		go func(h string) {
+			defer wg.Done()
+			release, _ := p.Acquire(context.Background(), h)
+			defer release()
+			n := atomic.AddInt32(&running, 1)
+			hn := atomic.AddInt32(hostRunning[h], 1)
+			mu.Lock()
+			if n > max {
+				max = n
+			}
+			if hn > perHost[h] {
+				perHost[h] = hn
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(hostRunning[h], -1)
+			atomic.AddInt32(&running, -1)
+		}(h)
+	}
+	wg.Wait()
+	return max, perHost
+}
+
+func TestWorkerPool_Acquire(t *testing.T) {
+	p := NewWorkerPool(3)
+	p.PerHost = HostLimit{Concurrency: 1}
+	p.Hosts["b.example.com"] = HostLimit{Concurrency: 2}
+	total, perHost := runConcurrently(p, []string{
+		"a.example.com", "a.example.com", "a.example.com",
+		"b.example.com", "b.example.com", "b.example.com",
+		"", "",
+	})
+	if total > 3 {
+		t.Errorf("total concurrency = %d, want <= 3", total)
+	}
+	if perHost["a.example.com"] != 1 {
+		t.Errorf("concurrency of a.example.com = %d, want 1", perHost["a.example.com"])
+	}
+	if perHost["b.example.com"] > 2 {
+		t.Errorf("concurrency of b.example.com = %d, want <= 2", perHost["b.example.com"])
+	}
+}
+
+func TestWorkerPool_MinDelay(t *testing.T) {
+	p := NewWorkerPool(3)
+	p.PerHost = HostLimit{MinDelay: 30 * time.Millisecond}
+	starts := make(chan time.Time, 3)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, _ := p.Acquire(context.Background(), "a.example.com")
+			starts <- time.Now()
+			release()
+		}()
+	}
+	wg.Wait()
+	close(starts)
+	var first, last time.Time
+	for s := range starts {
+		if first.IsZero() || s.Before(first) {
+			first = s
+		}
+		if s.After(last) {
+			last = s
+		}
+	}
+	if d := last.Sub(first); d < 55*time.Millisecond {
+		t.Errorf("jobs should start with the interval: %v", d)
+	}
+}
+
+func TestWorkerPool_AcquireCancel(t *testing.T) {
+	p := NewWorkerPool(1)
+	p.PerHost = HostLimit{Concurrency: 1}
+	release, err := p.Acquire(context.Background(), "a.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(ctx, "b.example.com"); err != context.DeadlineExceeded {
+		t.Errorf("Acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := p.Acquire(ctx, "a.example.com"); err != context.DeadlineExceeded {
+		t.Errorf("Acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	closed := make(chan error)
+	go func() {
+		_, err := p.Acquire(context.Background(), "b.example.com")
+		closed <- err
+	}()
+	for p.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	p.Close()
+	if err := <-closed; err != ErrPoolClosed {
+		t.Errorf("Acquire() error = %v, want %v", err, ErrPoolClosed)
+	}
+	if p.Waiting() != 0 {
+		t.Errorf("waiting = %d, want 0", p.Waiting())
+	}
+
+	// the slots of the given up jobs are not leaked
+	release()
+	p2 := NewWorkerPool(1)
+	p2.PerHost = HostLimit{Concurrency: 1}
+	p2.hosts = p.hosts
+	if release, err := p2.Acquire(context.Background(), "b.example.com"); err != nil {
+		t.Errorf("host slot should be released: %v", err)
+	} else {
+		release()
+	}
+}
@@ -0,0 +1,180 @@
+package watch
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+const (
+	// dateLayout is the layout of SkipDates.
+	dateLayout = "2006-01-02"
+	// maxScheduleIterations bounds the search for the next active time not to loop forever with the windows never matching.
+	maxScheduleIterations = 100000
+)
+
+type (
+	// ScheduleOptions are the options of the job schedule.
+	ScheduleOptions struct {
+		// Location is the time zone of the schedule, the windows and the skip dates.  nil means the local time zone.
+		Location *time.Location
+		// Windows are the periods the job runs in.  Empty means always.
+		Windows []ActiveWindow
+		// SkipDates are the dates the job doesn't run in the format of 2006-01-02.
+		SkipDates []string
+		// Jitter delays each scheduled run randomly up to this duration not to run all jobs at once.
+		// It is applied only to the intervals such as '@every'.
+		Jitter time.Duration
+	}
+	// ActiveWindow is the period of a day the job runs in.
+	ActiveWindow struct {
+		// Weekdays are the days the window starts.  Empty means every day.
+		Weekdays []time.Weekday
+		// Start and End are the offsets from the midnight.
+		// If End is not after Start, the window ends on the next day.
+		Start time.Duration
+		End   time.Duration
+	}
+	// jobSchedule is the cron.Schedule which skips the times out of the windows.
+	jobSchedule struct {
+		schedule  cron.Schedule
+		location  *time.Location
+		windows   []ActiveWindow
+		skipDates map[string]bool
+	}
+)
+
+// ParseTimeOfDay parses the time of day in the format of 15:04 to the offset from the midnight.
+func ParseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		// 24:00 is accepted for the end of the day
+		if s == "24:00" {
+			return 24 * time.Hour, nil
+		}
+		return 0, fmt.Errorf("invalid time of day: %s", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseWeekday parses the name of the day of the week such as 'mon' or 'Monday'.
+func ParseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday: %s", s)
+}
+
+// newJobSchedule parses the cron spec and applies the options.
+func newJobSchedule(spec string, options ScheduleOptions) (cron.Schedule, error) {
+	schedule, err := cron.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	return applyScheduleOptions(schedule, options)
+}
+
+// applyScheduleOptions wraps the schedule to skip the times out of the windows.
+func applyScheduleOptions(schedule cron.Schedule, options ScheduleOptions) (cron.Schedule, error) {
+	for _, d := range options.SkipDates {
+		if _, err := time.Parse(dateLayout, d); err != nil {
+			return nil, fmt.Errorf("invalid skip date: %s", d)
+		}
+	}
+	if options.Location == nil && len(options.Windows) == 0 && len(options.SkipDates) == 0 {
+		return schedule, nil
+	}
+	location := options.Location
+	if location == nil {
+		location = time.Local
+	}
+	skipDates := make(map[string]bool)
+	for _, d := range options.SkipDates {
+		skipDates[d] = true
+	}
+	return &jobSchedule{schedule, location, options.Windows, skipDates}, nil
+}
+
+// Next returns the next activation time in the windows.
+// Returns zero time if it is not found, then the job never runs.
+func (s *jobSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	for i := 0; i < maxScheduleIterations; i++ {
+		t = s.schedule.Next(t)
+		if t.IsZero() || s.active(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *jobSchedule) active(t time.Time) bool {
+	if s.skipDates[t.Format(dateLayout)] {
+		return false
+	}
+	if len(s.windows) == 0 {
+		return true
+	}
+	for _, w := range s.windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w ActiveWindow) contains(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	if w.Start < w.End {
+		return w.Start <= offset && offset < w.End && w.on(t.Weekday())
+	}
+	// the window over the midnight belongs to the day it starts.
+	if offset >= w.Start {
+		return w.on(t.Weekday())
+	}
+	if offset < w.End {
+		return w.on((t.Weekday() + 6) % 7)
+	}
+	return false
+}
+
+func (w ActiveWindow) on(d time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, weekday := range w.Weekdays {
+		if weekday == d {
+			return true
+		}
+	}
+	return false
+}
+
+// jitterable returns true if the jitter is applied to the schedule.
+// Only the intervals such as '@every' are delayed because the runs at the times of the cron expressions may be shifted out of the windows.
+func jitterable(schedule cron.Schedule) bool {
+	if s, ok := schedule.(*jobSchedule); ok {
+		schedule = s.schedule
+	}
+	switch schedule.(type) {
+	case cron.ConstantDelaySchedule, *AdaptiveSchedule:
+		return true
+	}
+	return false
+}
+
+// jitter returns the random delay up to the Jitter.
+func (o ScheduleOptions) jitter() time.Duration {
+	if o.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(o.Jitter)))
+}
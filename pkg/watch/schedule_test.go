@@ -0,0 +1,130 @@
+package watch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJobSchedule_Next(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	// 2026-10-16 is Friday
+	now := time.Date(2026, 10, 16, 22, 30, 0, 0, tokyo)
+	tests := []struct {
+		name    string
+		spec    string
+		options ScheduleOptions
+		now     time.Time
+		want    time.Time
+	}{
+		{
+			name:    "timezone",
+			spec:    "0 0 9 * * *",
+			options: ScheduleOptions{Location: tokyo},
+			now:     now.UTC(),
+			want:    time.Date(2026, 10, 17, 9, 0, 0, 0, tokyo),
+		},
+		{
+			name: "in window",
+			spec: "@every 20m",
+			options: ScheduleOptions{Location: tokyo, Windows: []ActiveWindow{
+				{Weekdays: weekdays, Start: 8 * time.Hour, End: 23 * time.Hour},
+			}},
+			now:  now,
+			want: now.Add(20 * time.Minute),
+		},
+		{
+			name: "next window",
+			spec: "0 0 * * * *",
+			options: ScheduleOptions{Location: tokyo, Windows: []ActiveWindow{
+				{Weekdays: weekdays, Start: 8 * time.Hour, End: 23 * time.Hour},
+			}},
+			now:  now,
+			want: time.Date(2026, 10, 19, 8, 0, 0, 0, tokyo),
+		},
+		{
+			name: "window over midnight",
+			spec: "0 0 * * * *",
+			options: ScheduleOptions{Location: tokyo, Windows: []ActiveWindow{
+				{Weekdays: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 2 * time.Hour},
+			}},
+			now:  time.Date(2026, 10, 17, 0, 30, 0, 0, tokyo),
+			want: time.Date(2026, 10, 17, 1, 0, 0, 0, tokyo),
+		},
+		{
+			name:    "skip dates",
+			spec:    "0 0 9 * * *",
+			options: ScheduleOptions{Location: tokyo, SkipDates: []string{"2026-10-17", "2026-10-18"}},
+			now:     now,
+			want:    time.Date(2026, 10, 19, 9, 0, 0, 0, tokyo),
+		},
+		{
+			name: "full day window",
+			spec: "@every 1h",
+			options: ScheduleOptions{Location: tokyo, Windows: []ActiveWindow{
+				{Weekdays: weekdays, Start: 8 * time.Hour, End: 8 * time.Hour},
+			}},
+			now:  now,
+			want: now.Add(time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newJobSchedule(tt.spec, tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.now); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"08:00", 8 * time.Hour, false},
+		{"23:30", 23*time.Hour + 30*time.Minute, false},
+		{"24:00", 24 * time.Hour, false},
+		{"8am", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeOfDay(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTimeOfDay(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+}
+
+func TestJitterable(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{"@every 10m", true},
+		{"0 0 9 * * *", false},
+		{"@daily", false},
+	}
+	windows := ScheduleOptions{Windows: []ActiveWindow{{Start: 8 * time.Hour, End: 23 * time.Hour}}}
+	for _, tt := range tests {
+		for _, options := range []ScheduleOptions{{}, windows} {
+			s, err := newJobSchedule(tt.spec, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := jitterable(s); got != tt.want {
+				t.Errorf("jitterable(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		}
+	}
+	if !jitterable(new(AdaptiveSchedule)) {
+		t.Error("adaptive schedule should be jitterable")
+	}
+}
@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultShutdownGracePeriod is the default time to wait for the cancelled checks.
+const DefaultShutdownGracePeriod = 5 * time.Second
+
+// ErrShuttingDown is returned if the check is requested after the shutdown started.
+var ErrShuttingDown = errors.New("shutting down")
+
+// Stop stops the schedule and returns from Run.  The running checks are not interrupted.
+func (e *Executor) Stop() {
+	e.jobsMu.Lock()
+	defer e.jobsMu.Unlock()
+	select {
+	case <-e.stopped:
+		return
+	default:
+	}
+	close(e.stopped)
+	e.scheduling = false
+	e.c.Stop()
+}
+
+// Shutdown stops the schedule, waits for the running checks and closes the store.
+// New checks and the checks waiting for the pool are rejected with ErrShuttingDown.
+// If ctx is done before the running checks finish, cancels them and returns the error of ctx.
+// The store is closed only if the cancelled checks finish within ShutdownGracePeriod.
+func (e *Executor) Shutdown(ctx context.Context) error {
+	e.Stop()
+	e.mu.Lock()
+	e.closed = true
+	e.mu.Unlock()
+	e.Pool.Close()
+
+	done := make(chan struct{})
+	go func() {
+		e.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return e.closeStore()
+	case <-ctx.Done():
+	}
+
+	// the cancelled checks still store their results.
+	e.cancel()
+	err := fmt.Errorf("failed to wait for running checks: %w", ctx.Err())
+	grace := e.ShutdownGracePeriod
+	if grace <= 0 {
+		grace = DefaultShutdownGracePeriod
+	}
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		return err
+	}
+	if cerr := e.closeStore(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func (e *Executor) closeStore() error {
+	if closer, ok := e.store.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close store: %w", err)
+		}
+	}
+	return nil
+}
+
+// begin registers the check to be waited by Shutdown.
+// Returns false if the shutdown already started.
+func (e *Executor) begin() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return false
+	}
+	e.running.Add(1)
+	return true
+}
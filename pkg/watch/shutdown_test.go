@@ -0,0 +1,159 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+	"github.com/uphy/watch-web/pkg/watch/store"
+)
+
+func TestExecutor_Shutdown(t *testing.T) {
+	e := NewExecutor(store.NewMemoryStore(), logrus.New())
+	e.ShutdownGracePeriod = 10 * time.Millisecond
+	src := &blockingSource{started: make(chan struct{}, 1), release: make(chan struct{})}
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	checked := make(chan error, 1)
+	go func() {
+		_, err := e.Check(job)
+		checked <- err
+	}()
+	<-src.started
+
+	// timeout while the check is running
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := e.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := e.Check(job); err != ErrShuttingDown {
+		t.Errorf("Check() error = %v, want %v", err, ErrShuttingDown)
+	}
+
+	close(src.release)
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	select {
+	case err := <-checked:
+		if err != nil {
+			t.Errorf("Check() error = %v", err)
+		}
+	default:
+		t.Error("Shutdown() should wait for the running check")
+	}
+	if v, err := e.GetJobValue(job.ID()); err != nil || v == nil {
+		t.Errorf("GetJobValue() = %v, %v, want the stored value", v, err)
+	}
+}
+
+type cancellableSource struct {
+	started chan struct{}
+}
+
+func (s *cancellableSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
+	s.started <- struct{}{}
+	<-ctx.Context().Done()
+	return nil, ctx.Context().Err()
+}
+
+type closingStore struct {
+	*store.MemoryStore
+	closed bool
+}
+
+func (s *closingStore) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestExecutor_ShutdownCancelled(t *testing.T) {
+	s := &closingStore{MemoryStore: store.NewMemoryStore()}
+	e := NewExecutor(s, logrus.New())
+	src := &cancellableSource{started: make(chan struct{}, 1)}
+	job := NewJob(&domain.JobInfo{ID: "job"}, src, nil)
+	if err := e.AddJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	checked := make(chan error, 1)
+	go func() {
+		_, err := e.Check(job)
+		checked <- err
+	}()
+	<-src.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := e.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case err := <-checked:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Check() error = %v, want %v", err, context.Canceled)
+		}
+	default:
+		t.Error("Shutdown() should wait for the cancelled check")
+	}
+	if status, err := s.GetJobStatus(job.ID()); err != nil || status.Status != domain.StatusError {
+		t.Errorf("GetJobStatus() = %v, %v, want the failed status", status, err)
+	}
+	if !s.closed {
+		t.Error("store should be closed after the cancelled check finished")
+	}
+}
+
+func TestExecutor_ShutdownQueued(t *testing.T) {
+	s := store.NewMemoryStore()
+	e := NewExecutor(s, logrus.New())
+	e.Pool = NewWorkerPool(1)
+	src := &blockingSource{started: make(chan struct{}, 1), release: make(chan struct{})}
+	running := NewJob(&domain.JobInfo{ID: "running"}, src, nil)
+	queued := NewJob(&domain.JobInfo{ID: "queued"}, src, nil)
+	for _, j := range []*Job{running, queued} {
+		if err := e.AddJob(j, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	go e.Check(running)
+	<-src.started
+	checked := make(chan error, 1)
+	go func() {
+		_, err := e.Check(queued)
+		checked <- err
+	}()
+	for e.Pool.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- e.Shutdown(context.Background())
+	}()
+	// the queued check is given up without waiting for the running one
+	select {
+	case err := <-checked:
+		if err != ErrShuttingDown {
+			t.Errorf("Check() error = %v, want %v", err, ErrShuttingDown)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued check should be given up")
+	}
+	close(src.release)
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if _, err := s.GetJobStatus("queued"); err != store.ErrNotFound {
+		t.Errorf("status of the queued job must not be stored: %v", err)
+	}
+	if runs, _ := s.GetJobRuns("queued", 0); len(runs) != 0 {
+		t.Errorf("runs of the queued job must not be recorded: %v", runs)
+	}
+}
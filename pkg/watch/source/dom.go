@@ -6,6 +6,7 @@ import (
 	"github.com/uphy/watch-web/pkg/domain/value"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -31,8 +32,21 @@ func NewDOMSource(url, selector string, encoding encoding.Encoding) *DOMSource {
 	}
 }
 
+// Host returns the host of the URL.
+func (d *DOMSource) Host() string {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
 func (d *DOMSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
-	resp, err := http.Get(d.URL)
+	req, err := http.NewRequestWithContext(ctx.Context(), "GET", d.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
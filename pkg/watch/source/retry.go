@@ -1,8 +1,10 @@
 package source
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/uphy/watch-web/pkg/domain"
 	"github.com/uphy/watch-web/pkg/domain/retry"
@@ -20,11 +22,13 @@ type (
 		source      domain.Source
 		emptyAction *EmptyAction
 		retrier     *retry.Retrier
+		// Timeout is the timeout of each fetch.  0 means no timeout other than the job's one.
+		Timeout time.Duration
 	}
 )
 
 func NewRetrySource(src domain.Source, emptyAction *EmptyAction, retrier *retry.Retrier) *SourceWithRetry {
-	return &SourceWithRetry{src, emptyAction, retrier}
+	return &SourceWithRetry{source: src, emptyAction: emptyAction, retrier: retrier}
 }
 
 func (s *SourceWithRetry) Fetch(ctx *domain.JobContext) (value.Value, error) {
@@ -32,7 +36,7 @@ func (s *SourceWithRetry) Fetch(ctx *domain.JobContext) (value.Value, error) {
 		return s.fetch(ctx)
 	}
 	var v value.Value
-	err := s.retrier.Run(func(retryContext *retry.RetryContext) error {
+	err := s.retrier.RunContext(ctx.Context(), func(retryContext *retry.RetryContext) error {
 		var err error
 		v, err = s.fetch(ctx)
 		return err
@@ -43,7 +47,20 @@ func (s *SourceWithRetry) Fetch(ctx *domain.JobContext) (value.Value, error) {
 	return v, nil
 }
 
+// Host returns the host of the underlying source.
+func (s *SourceWithRetry) Host() string {
+	if h, ok := s.source.(domain.HostSource); ok {
+		return h.Host()
+	}
+	return ""
+}
+
 func (s *SourceWithRetry) fetch(ctx *domain.JobContext) (value.Value, error) {
+	if s.Timeout > 0 {
+		c, cancel := context.WithTimeout(ctx.Context(), s.Timeout)
+		defer cancel()
+		ctx = ctx.WithContext(c)
+	}
 	v, err := s.source.Fetch(ctx)
 	if err != nil {
 		return nil, err
@@ -25,7 +25,7 @@ func NewShellSource(command string) *ShellSource {
 
 func (c *ShellSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
 	ctx.Log.WithField("command", c.Command).Debug("Run shell command.")
-	cmd := exec.Command("sh", "-c", c.Command)
+	cmd := exec.CommandContext(ctx.Context(), "sh", "-c", c.Command)
 	for _, env := range os.Environ() {
 		if strings.HasPrefix(env, "PATH=") {
 			dir, err := os.Getwd()
@@ -38,11 +38,25 @@ func (c *ShellSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
 			cmd.Env = append(cmd.Env, env)
 		}
 	}
-	b, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute shell command: command=%s, err=%v", c.Command, err)
+	// CombinedOutput may not return on the cancellation until the child processes of the shell close the output.
+	type output struct {
+		b   []byte
+		err error
+	}
+	done := make(chan output, 1)
+	go func() {
+		b, err := cmd.CombinedOutput()
+		done <- output{b, err}
+	}()
+	select {
+	case o := <-done:
+		if o.err != nil {
+			return nil, fmt.Errorf("failed to execute shell command: command=%s, err=%v", c.Command, o.err)
+		}
+		return value.NewStringValue(string(o.b)), nil
+	case <-ctx.Context().Done():
+		return nil, fmt.Errorf("failed to execute shell command: command=%s, err=%w", c.Command, ctx.Context().Err())
 	}
-	return value.NewStringValue(string(b)), nil
 }
 
 func (c *ShellSource) String() string {
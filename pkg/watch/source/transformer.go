@@ -2,6 +2,8 @@ package source
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/uphy/watch-web/pkg/domain/value"
 
 	"github.com/sirupsen/logrus"
@@ -25,6 +27,10 @@ func (f *TransformerSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
 		return nil, err
 	}
 	ctx.Log.WithField("source", v).Debug("Start transformer chain.")
+	start := time.Now()
+	defer func() {
+		ctx.Timings.AddTransform(time.Since(start))
+	}()
 	for _, transformer := range f.transformers {
 		transformed, err := transformer.Transform(ctx, v)
 		if err != nil {
@@ -42,6 +48,14 @@ func (f *TransformerSource) Fetch(ctx *domain.JobContext) (value.Value, error) {
 	return v, nil
 }
 
+// Host returns the host of the underlying source.
+func (f *TransformerSource) Host() string {
+	if h, ok := f.source.(domain.HostSource); ok {
+		return h.Host()
+	}
+	return ""
+}
+
 func (f *TransformerSource) String() string {
 	return fmt.Sprintf("Transformer[source=%v, transformers=%v]", f.source, f.transformers)
 }
@@ -11,8 +11,9 @@ import (
 )
 
 const (
-	fileNameVars  = "vars"
-	jobFilePrefix = "job-"
+	fileNameVars      = "vars"
+	jobFilePrefix     = "job-"
+	historyFilePrefix = "history-"
 )
 
 type (
@@ -154,3 +155,23 @@ func (s *DirectoryStore) SetJobStatus(jobID string, status *domain.JobStatus) er
 	f.Status = status
 	return s.writeJob(jobID, f)
 }
+
+func (s *DirectoryStore) AddJobRun(jobID string, run *domain.JobRun, retention domain.HistoryRetention) error {
+	var runs []domain.JobRun
+	if err := s.read(historyFilePrefix+jobID, &runs); err != nil {
+		return err
+	}
+	runs = append([]domain.JobRun{*run}, runs...)
+	return s.write(historyFilePrefix+jobID, retention.Apply(runs, time.Now()))
+}
+
+func (s *DirectoryStore) GetJobRuns(jobID string, limit int) ([]domain.JobRun, error) {
+	var runs []domain.JobRun
+	if err := s.read(historyFilePrefix+jobID, &runs); err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
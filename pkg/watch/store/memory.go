@@ -1,6 +1,7 @@
 package store
 
 import (
+	"sync"
 	"time"
 
 	"github.com/uphy/watch-web/pkg/domain"
@@ -11,19 +12,42 @@ type (
 		jobStatuses map[string]domain.JobStatus
 		jobValues   map[string]string
 		values      map[string]string
+		locks       map[string]memoryLock
+		leases      map[string]memoryLease
+		runs        map[string][]domain.JobRun
+		mu          sync.Mutex
+	}
+	memoryLock struct {
+		token  string
+		expire time.Time
+	}
+	memoryLease struct {
+		holder string
+		expire time.Time
 	}
 )
 
 func NewMemoryStore() *MemoryStore {
-	return &MemoryStore{make(map[string]domain.JobStatus), make(map[string]string), make(map[string]string)}
+	return &MemoryStore{
+		jobStatuses: make(map[string]domain.JobStatus),
+		jobValues:   make(map[string]string),
+		values:      make(map[string]string),
+		locks:       make(map[string]memoryLock),
+		leases:      make(map[string]memoryLease),
+		runs:        make(map[string][]domain.JobRun),
+	}
 }
 
 func (s *MemoryStore) SetTemp(key string, value string, expire time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.values[key] = value
 	return nil
 }
 
 func (s *MemoryStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	v, exist := s.values[key]
 	if !exist {
 		return "", ErrNotFound
@@ -32,6 +56,8 @@ func (s *MemoryStore) Get(key string) (string, error) {
 }
 
 func (s *MemoryStore) GetJobValue(jobID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	v, exist := s.jobValues[jobID]
 	if !exist {
 		return "", ErrNotFound
@@ -40,11 +66,15 @@ func (s *MemoryStore) GetJobValue(jobID string) (string, error) {
 }
 
 func (s *MemoryStore) SetJobValue(jobID string, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.jobValues[jobID] = value
 	return nil
 }
 
 func (s *MemoryStore) GetJobStatus(jobID string) (*domain.JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	v, exist := s.jobStatuses[jobID]
 	if !exist {
 		return nil, ErrNotFound
@@ -53,6 +83,70 @@ func (s *MemoryStore) GetJobStatus(jobID string) (*domain.JobStatus, error) {
 }
 
 func (s *MemoryStore) SetJobStatus(jobID string, status *domain.JobStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.jobStatuses[jobID] = *status
 	return nil
 }
+
+func (s *MemoryStore) AddJobRun(jobID string, run *domain.JobRun, retention domain.HistoryRetention) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := append([]domain.JobRun{*run}, s.runs[jobID]...)
+	s.runs[jobID] = retention.Apply(runs, time.Now())
+	return nil
+}
+
+func (s *MemoryStore) GetJobRuns(jobID string, limit int) ([]domain.JobRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := s.runs[jobID]
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return append([]domain.JobRun(nil), runs...), nil
+}
+
+func (s *MemoryStore) TryLock(key string, ttl time.Duration) (string, bool, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if lock, exist := s.locks[key]; exist && now.Before(lock.expire) {
+		return "", false, nil
+	}
+	s.locks[key] = memoryLock{token, now.Add(ttl)}
+	return token, true, nil
+}
+
+func (s *MemoryStore) Unlock(key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lock, exist := s.locks[key]; exist && lock.token == token {
+		delete(s.locks, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) AcquireLease(key, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if lease, exist := s.leases[key]; exist && lease.holder != holder && now.Before(lease.expire) {
+		return false, nil
+	}
+	s.leases[key] = memoryLease{holder, now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) ReleaseLease(key, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lease, exist := s.leases[key]; exist && lease.holder == holder {
+		delete(s.leases, key)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v7"
@@ -11,8 +12,23 @@ import (
 const (
 	redisPrefixValue  = "v:"
 	redisPrefixStatus = "s:"
+	redisPrefixLock   = "l:"
+	redisPrefixLease  = "e:"
+	redisPrefixRuns   = "h:"
 )
 
+// redisUnlockScript deletes the lock only if it is held by the caller.
+// It is also used to release the lease.
+var redisUnlockScript = redis.NewScript(`if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`)
+
+// redisAcquireLeaseScript sets the lease if it is not held or held by the caller.
+var redisAcquireLeaseScript = redis.NewScript(`local holder = redis.call("get", KEYS[1])
+if holder == false or holder == ARGV[1] then
+	redis.call("set", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0`)
+
 type (
 	RedisStore struct {
 		client *redis.Client
@@ -27,7 +43,12 @@ type (
 )
 
 func NewRedisStore(client *redis.Client) *RedisStore {
-	return &RedisStore{client}
+	return &RedisStore{client: client}
+}
+
+// Close closes the connections to the Redis after the pending commands are done.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
 }
 
 func (s *RedisStore) SetTemp(key string, value string, expire time.Duration) error {
@@ -90,3 +111,72 @@ func (s *RedisStore) SetJobStatus(jobID string, status *domain.JobStatus) error
 	}
 	return s.client.Set(redisPrefixStatus+jobID, string(b), 0).Err()
 }
+
+// AddJobRun adds the run to the sorted set scored by the start time and trims it in a transaction.
+func (s *RedisStore) AddJobRun(jobID string, run *domain.JobRun, retention domain.HistoryRetention) error {
+	b, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	key := redisPrefixRuns + jobID
+	maxRuns := retention.MaxRuns
+	if maxRuns <= 0 {
+		maxRuns = domain.DefaultHistoryMaxRuns
+	}
+	_, err = s.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(key, &redis.Z{Score: float64(run.Start.UnixNano() / int64(time.Millisecond)), Member: string(b)})
+		pipe.ZRemRangeByRank(key, 0, -int64(maxRuns)-1)
+		if retention.MaxAge > 0 {
+			expire := time.Now().Add(-retention.MaxAge).UnixNano() / int64(time.Millisecond)
+			pipe.ZRemRangeByScore(key, "-inf", "("+strconv.FormatInt(expire, 10))
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) GetJobRuns(jobID string, limit int) ([]domain.JobRun, error) {
+	stop := int64(limit) - 1
+	if limit <= 0 {
+		stop = -1
+	}
+	values, err := s.client.ZRevRange(redisPrefixRuns+jobID, 0, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]domain.JobRun, len(values))
+	for i, v := range values {
+		if err := json.Unmarshal([]byte(v), &runs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+func (s *RedisStore) TryLock(key string, ttl time.Duration) (string, bool, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", false, err
+	}
+	ok, err := s.client.SetNX(redisPrefixLock+key, token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+func (s *RedisStore) Unlock(key, token string) error {
+	return redisUnlockScript.Run(s.client, []string{redisPrefixLock + key}, token).Err()
+}
+
+func (s *RedisStore) AcquireLease(key, holder string, ttl time.Duration) (bool, error) {
+	acquired, err := redisAcquireLeaseScript.Run(s.client, []string{redisPrefixLease + key}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+func (s *RedisStore) ReleaseLease(key, holder string) error {
+	return redisUnlockScript.Run(s.client, []string{redisPrefixLease + key}, holder).Err()
+}
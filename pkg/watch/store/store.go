@@ -1,9 +1,20 @@
 package store
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 )
 
 var (
 	ErrNotFound = errors.New("value not found")
 )
+
+// newLockToken generates the random token identifying the owner of a lock.
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -22,7 +22,7 @@ func (f *FilterTransformer) Transform(ctx *domain.JobContext, v value.Value) (va
 	a := v.JSONArray()
 	filtered := make(value.JSONArray, 0)
 	for _, elm := range a {
-		result, err := f.script.Evaluate(map[string]interface{}{
+		result, err := f.script.Evaluate(ctx.Context(), map[string]interface{}{
 			"source": elm,
 		})
 		if err != nil {
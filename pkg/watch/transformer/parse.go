@@ -0,0 +1,110 @@
+package transformer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/value"
+)
+
+const (
+	ParseTypeNumber ParseType = "number"
+	ParseTypeDate   ParseType = "date"
+
+	// parseRawSuffix is the suffix of the key which keeps the original value.
+	// The key starts with value.InternalPropertyPrefix so that it is not used for diff.
+	parseRawSuffix = "_raw"
+)
+
+type (
+	ParseType  string
+	ParseField struct {
+		Type     ParseType
+		Layouts  []string
+		Location *time.Location
+	}
+	ParseTransformer struct {
+		fields map[string]*ParseField
+	}
+)
+
+func NewParseTransformer(fields map[string]*ParseField) (*ParseTransformer, error) {
+	for key, field := range fields {
+		switch field.Type {
+		case ParseTypeNumber, ParseTypeDate:
+		default:
+			return nil, fmt.Errorf("unsupported parse type: key=%s, type=%s", key, field.Type)
+		}
+	}
+	return &ParseTransformer{fields}, nil
+}
+
+func (t *ParseTransformer) Transform(ctx *domain.JobContext, v value.Value) (value.Value, error) {
+	switch v.Type() {
+	case value.ValueTypeJSONObject:
+		parsed, err := t.parseObject(v.JSONObject())
+		if err != nil {
+			return nil, err
+		}
+		return value.NewJSONObject(parsed), nil
+	case value.ValueTypeJSONArray:
+		result := make([]interface{}, 0)
+		for _, elm := range v.JSONArray() {
+			obj, err := value.ConvertInterfaceAs(elm, value.ValueTypeJSONObject)
+			if err != nil {
+				return nil, err
+			}
+			parsed, err := t.parseObject(obj.JSONObject())
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, parsed)
+		}
+		return value.NewJSONArray(result), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type: %s", v.Type())
+	}
+}
+
+func (t *ParseTransformer) parseObject(obj value.JSONObject) (map[string]interface{}, error) {
+	parsed := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		parsed[k] = v
+	}
+	for key, field := range t.fields {
+		raw, exist := obj[key]
+		if !exist || raw == nil {
+			continue
+		}
+		s := fmt.Sprint(raw)
+		var converted interface{}
+		switch field.Type {
+		case ParseTypeNumber:
+			n, err := value.ParseNumber(s)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse as number: key=%s, err=%w", key, err)
+			}
+			converted = value.NumberInterface(n)
+		case ParseTypeDate:
+			d, err := value.ParseTime(s, field.Layouts, field.Location)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse as date: key=%s, err=%w", key, err)
+			}
+			converted = d
+		}
+		parsed[key] = converted
+		parsed[value.InternalPropertyPrefix+key+parseRawSuffix] = raw
+	}
+	return parsed, nil
+}
+
+func (t *ParseTransformer) String() string {
+	keys := make([]string, 0, len(t.fields))
+	for k, f := range t.fields {
+		keys = append(keys, fmt.Sprintf("%s:%s", k, f.Type))
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("Parse[fields=%v]", keys)
+}
@@ -1,7 +1,9 @@
 package transformer
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/uphy/watch-web/pkg/domain"
 	"github.com/uphy/watch-web/pkg/domain/retry"
@@ -12,21 +14,23 @@ type (
 	TransformerWithRetry struct {
 		transformer domain.Transformer
 		retrier     *retry.Retrier
+		// Timeout is the timeout of each transform.  0 means no timeout other than the job's one.
+		Timeout time.Duration
 	}
 )
 
 func NewRetryTransform(transformer domain.Transformer, retrier *retry.Retrier) *TransformerWithRetry {
-	return &TransformerWithRetry{transformer, retrier}
+	return &TransformerWithRetry{transformer: transformer, retrier: retrier}
 }
 
 func (s *TransformerWithRetry) Transform(ctx *domain.JobContext, v value.Value) (value.Value, error) {
 	if s.retrier == nil {
-		return s.transformer.Transform(ctx, v)
+		return s.transform(ctx, v)
 	}
 	var result value.Value
-	err := s.retrier.Run(func(retryContext *retry.RetryContext) error {
+	err := s.retrier.RunContext(ctx.Context(), func(retryContext *retry.RetryContext) error {
 		var err error
-		result, err = s.transformer.Transform(ctx, v)
+		result, err = s.transform(ctx, v)
 		return err
 	})
 	if err != nil {
@@ -35,6 +39,15 @@ func (s *TransformerWithRetry) Transform(ctx *domain.JobContext, v value.Value)
 	return result, nil
 }
 
+func (s *TransformerWithRetry) transform(ctx *domain.JobContext, v value.Value) (value.Value, error) {
+	if s.Timeout > 0 {
+		c, cancel := context.WithTimeout(ctx.Context(), s.Timeout)
+		defer cancel()
+		ctx = ctx.WithContext(c)
+	}
+	return s.transformer.Transform(ctx, v)
+}
+
 func (s *TransformerWithRetry) String() string {
 	var retry = ""
 	if s.retrier != nil {
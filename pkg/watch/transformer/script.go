@@ -18,7 +18,7 @@ func NewScriptTransformer(script domain.Script) (*ScriptTransformer, error) {
 }
 
 func (t ScriptTransformer) Transform(ctx *domain.JobContext, v value.Value) (value.Value, error) {
-	result, err := t.script.Evaluate(map[string]interface{}{
+	result, err := t.script.Evaluate(ctx.Context(), map[string]interface{}{
 		"source": v,
 	})
 
@@ -0,0 +1,42 @@
+package transformer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/uphy/watch-web/pkg/domain"
+	"github.com/uphy/watch-web/pkg/domain/schema"
+	"github.com/uphy/watch-web/pkg/domain/value"
+)
+
+type (
+	ValidateTransformer struct {
+		schema *schema.Schema
+	}
+)
+
+func NewValidateTransformer(schema *schema.Schema) *ValidateTransformer {
+	return &ValidateTransformer{schema}
+}
+
+// Transform validates the value and returns it as is.
+// Fails if the value doesn't satisfy the schema so that the broken value is not stored.
+func (t *ValidateTransformer) Transform(ctx *domain.JobContext, v value.Value) (value.Value, error) {
+	// normalize the value to the types of encoding/json
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value for validation: %w", err)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value for validation: %w", err)
+	}
+	if errs := t.schema.Validate(normalized); errs != nil {
+		return nil, fmt.Errorf("validation failed: %w", errs)
+	}
+	return v, nil
+}
+
+func (t *ValidateTransformer) String() string {
+	return "Validate[]"
+}
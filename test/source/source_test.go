@@ -145,7 +145,8 @@ func TestAll(t *testing.T) {
 }
 
 func compareResult(reporter *reporter, label string, expected, actual value.ItemList) {
-	if !reflect.DeepEqual(expected, actual) {
+	// compare as JSON so that the types of the values are compared as well
+	if expected.JSON() != actual.JSON() {
 		reporter.Errorf(`%s wrong:
 expected:
 %s